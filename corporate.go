@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"nse/lib/nse"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	announcementsCmdUse      = "announcements"
+	announcementsCmdShort    = "Get the corporate announcements of a symbol"
+	boardMeetingsCmdUse      = "board-meetings"
+	boardMeetingsCmdShort    = "Get the board meetings of a symbol"
+	corpActionsCmdUse        = "corp-actions"
+	corpActionsCmdShort      = "Get the corporate actions of a symbol"
+	companyInfoCmdUse        = "company-info"
+	companyInfoCmdShort      = "Get the directory, registrar and annual reports of a symbol"
+	oldestFirstFlagName      = "oldest-first"
+	oldestFirstFlagDesc      = "List the oldest entries first instead of the latest"
+	corporateDateTimeFormat  = "2006-01-02 15:04"
+	corporateTextColumnWidth = 80
+)
+
+// corporateCmd returns a command printing the corporate info of --symbol
+// with show. Commands listing dated entries get --oldest-first.
+func corporateCmd(use, short string, dated bool, show func(info *nse.EquityCorporateInfo, oldestFirst bool)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			symbol, _ := cmd.Flags().GetString(symbolFlagName)
+			info, err := client.CorporateInfo(cmd.Context(), symbol)
+			if err != nil {
+				return err
+			}
+			oldestFirst, _ := cmd.Flags().GetBool(oldestFirstFlagName)
+			show(info, oldestFirst)
+			return nil
+		},
+	}
+	cmd.Flags().StringP(symbolFlagName, symbolFlagShort, symbolFlagDefault, symbolFlagDescription)
+	if dated {
+		cmd.Flags().Bool(oldestFirstFlagName, false, oldestFirstFlagDesc)
+	}
+	return cmd
+}
+
+// sortByDate sorts data oldest first, or latest first
+func sortByDate(data sort.Interface, oldestFirst bool) {
+	if oldestFirst {
+		sort.Stable(data)
+		return
+	}
+	sort.Stable(sort.Reverse(data))
+}
+
+// formatDate formats d as yyyy-mm-dd, with the time of day if it has one,
+// and "-" if it is not set
+func formatDate(d nse.Date) string {
+	switch {
+	case d.IsZero():
+		return "-"
+	case d.Hour() == 0 && d.Minute() == 0 && d.Second() == 0:
+		return d.Format(dateFlagFormat)
+	}
+	return d.Format(corporateDateTimeFormat)
+}
+
+// truncate shortens s to n runes, flattening it to one line
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+var announcementsCmd = corporateCmd(announcementsCmdUse, announcementsCmdShort, true, func(info *nse.EquityCorporateInfo, oldestFirst bool) {
+	announcements := info.Corporate.Announcements
+	sortByDate(announcements, oldestFirst)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Date\tSubject\tDetails\tAttachment")
+	for _, a := range announcements {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formatDate(a.AnDt), a.Desc, truncate(a.AttchmntText, corporateTextColumnWidth), a.AttchmntFile)
+	}
+	w.Flush()
+})
+
+var boardMeetingsCmd = corporateCmd(boardMeetingsCmdUse, boardMeetingsCmdShort, true, func(info *nse.EquityCorporateInfo, oldestFirst bool) {
+	meetings := info.Corporate.BoardMeetings
+	sortByDate(meetings, oldestFirst)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Date\tPurpose\tDetails\tIntimated")
+	for _, m := range meetings {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formatDate(m.BmDate), m.BmPurpose, truncate(m.BmDesc, corporateTextColumnWidth), formatDate(m.BmTimestamp))
+	}
+	w.Flush()
+})
+
+var corpActionsCmd = corporateCmd(corpActionsCmdUse, corpActionsCmdShort, true, func(info *nse.EquityCorporateInfo, oldestFirst bool) {
+	actions := info.Corporate.CorporateActions
+	sortByDate(actions, oldestFirst)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Ex-date\tRecord date\tSeries\tFace value\tSubject")
+	for _, a := range actions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%s\n", formatDate(a.ExDate), formatDate(a.RecDate), a.Series, float64(a.FaceVal), a.Subject)
+	}
+	w.Flush()
+})
+
+var companyInfoCmd = corporateCmd(companyInfoCmdUse, companyInfoCmdShort, false, func(info *nse.EquityCorporateInfo, _ bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	printDirectory := func(title string, entries []nse.DirectoryDetails) {
+		for _, d := range entries {
+			fmt.Fprintf(w, "%s\t%s\n", title, d.Office)
+			fmt.Fprintf(w, "  Address\t%s, %s %s\n", d.Address, d.City, d.Pincode)
+			fmt.Fprintf(w, "  Contact\t%s\n", d.SMName)
+			fmt.Fprintf(w, "  Telephone\t%s\n", d.Telephone)
+			fmt.Fprintf(w, "  Email\t%s\n", d.Email)
+			fmt.Fprintf(w, "  Website\t%s\n", d.WebAddress)
+		}
+	}
+	printDirectory("Company", info.Corporate.CompanyDirectory)
+	printDirectory("Registrar", info.Corporate.TransferAgentDetail)
+	for _, r := range info.Corporate.AnnualReport {
+		fmt.Fprintf(w, "Annual report %s-%s\t%s\n", r.FromYr, r.ToYr, r.FileName)
+	}
+	w.Flush()
+})
+
+func init() {
+	rootCmd.AddCommand(announcementsCmd, boardMeetingsCmd, corpActionsCmd, companyInfoCmd)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"nse/lib/nse"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	indexCmdUse        = "index"
+	indexCmdShort      = "Get the live snapshot of an index and its constituents"
+	sortFlagName       = "sort"
+	sortFlagDefault    = "change"
+	reverseFlagName    = "reverse"
+	reverseFlagDesc    = "Reverse the sort order"
+	snapshotTimeFormat = "02-Jan-2006 15:04"
+	indicesCmdUse      = "indices"
+	indicesCmdShort    = "List the live values of all indices by category"
+	categoryFlagName   = "category"
+	categoryFlagShort  = "c"
+	categoryFlagDesc   = "Only list indices of categories containing this, e.g. sectoral"
+)
+
+// constituentSorts are the orders of the constituent table. Numbers sort
+// highest first and symbols alphabetically.
+var constituentSorts = map[string]func(a, b nse.IndexEquityInfo) bool{
+	"symbol": func(a, b nse.IndexEquityInfo) bool { return a.Symbol < b.Symbol },
+	"price":  func(a, b nse.IndexEquityInfo) bool { return a.LastPrice > b.LastPrice },
+	"change": func(a, b nse.IndexEquityInfo) bool { return a.PChange > b.PChange },
+	"volume": func(a, b nse.IndexEquityInfo) bool { return a.TotalTradedVolume > b.TotalTradedVolume },
+	"value":  func(a, b nse.IndexEquityInfo) bool { return a.TotalTradedValue > b.TotalTradedValue },
+	"wkh":    func(a, b nse.IndexEquityInfo) bool { return a.NearWKH > b.NearWKH },
+	"wkl":    func(a, b nse.IndexEquityInfo) bool { return a.NearWKL > b.NearWKL },
+	"30d":    func(a, b nse.IndexEquityInfo) bool { return a.PerChange30d > b.PerChange30d },
+	"365d":   func(a, b nse.IndexEquityInfo) bool { return a.PerChange365d > b.PerChange365d },
+}
+
+// sortKeys returns the names of constituentSorts, for the --sort help
+func sortKeys() string {
+	keys := make([]string, 0, len(constituentSorts))
+	for k := range constituentSorts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+var indexCmd = &cobra.Command{
+	Use:   indexCmdUse,
+	Short: indexCmdShort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString(indexNameFlagName)
+		key, _ := cmd.Flags().GetString(sortFlagName)
+		reverse, _ := cmd.Flags().GetBool(reverseFlagName)
+		less, ok := constituentSorts[strings.ToLower(key)]
+		if !ok {
+			return fmt.Errorf("invalid --%s %q, want one of %s", sortFlagName, key, sortKeys())
+		}
+
+		snapshot, err := client.IndexSnapshot(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+		constituents := snapshot.Constituents()
+		sort.SliceStable(constituents, func(i, j int) bool {
+			if reverse {
+				return less(constituents[j], constituents[i])
+			}
+			return less(constituents[i], constituents[j])
+		})
+		printIndexSnapshot(snapshot, constituents)
+		return nil
+	},
+}
+
+func printIndexSnapshot(snapshot *nse.IndexDetails, constituents []nse.IndexEquityInfo) {
+	m := snapshot.Metadata
+	fmt.Printf("%s  %.2f  %+.2f (%+.2f%%)  open %.2f  high %.2f  low %.2f\n",
+		m.IndexName, m.Last, m.Change, m.PercChange, m.Open, m.High, m.Low)
+	fmt.Printf("Advances %d  Declines %d  Unchanged %d  %s: %s",
+		snapshot.Advance.Advances, snapshot.Advance.Declines, snapshot.Advance.Unchanged,
+		snapshot.MarketStatus.Market, snapshot.MarketStatus.MarketStatus)
+	if !snapshot.Timestamp.IsZero() {
+		fmt.Printf(" as of %s", snapshot.Timestamp.Format(snapshotTimeFormat))
+	}
+	fmt.Println()
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Symbol\tLast\tChange\tChange %\tVolume\tFrom 52w high %\tFrom 52w low %\t30d %\t365d %\t")
+	for _, c := range constituents {
+		fmt.Fprintf(w, "%s\t%.2f\t%+.2f\t%+.2f\t%.0f\t%.2f\t%.2f\t%+.2f\t%+.2f\t\n",
+			c.Symbol, c.LastPrice, c.Change, c.PChange, c.TotalTradedVolume,
+			c.NearWKH, c.NearWKL, c.PerChange30d, c.PerChange365d)
+	}
+	w.Flush()
+}
+
+var indicesCmd = &cobra.Command{
+	Use:   indicesCmdUse,
+	Short: indicesCmdShort,
+	Long: indicesCmdShort + `. The names listed can be passed to
+nse index --name and nse index-history --name.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		category, _ := cmd.Flags().GetString(categoryFlagName)
+		indices, err := client.AllIndices(cmd.Context())
+		if err != nil {
+			return err
+		}
+		categories := indices.Categories()
+		if category != "" {
+			categories = indices.Category(category)
+		}
+		if len(categories) == 0 {
+			return fmt.Errorf("no index category matches %q", category)
+		}
+		printIndices(indices, categories)
+		return nil
+	},
+}
+
+func printIndices(indices *nse.AllIndicesData, categories []nse.IndexCategory) {
+	fmt.Printf("Advances %d  Declines %d  Unchanged %d", indices.Advances, indices.Declines, indices.Unchanged)
+	if !indices.Timestamp.IsZero() {
+		fmt.Printf("  as of %s", indices.Timestamp.Format(snapshotTimeFormat))
+	}
+	fmt.Println()
+
+	// a zero valuation is one NSE does not publish, e.g. for INDIA VIX
+	valuation := func(v nse.Float) string {
+		if v == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", v)
+	}
+	for _, c := range categories {
+		fmt.Printf("\n%s\n", c.Name)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "Index\tLast\tChange\tChange %\tP/E\tP/B\tDiv yield\tAdv/Dec\t")
+		for _, q := range c.Indices {
+			fmt.Fprintf(w, "%s\t%.2f\t%+.2f\t%+.2f\t%s\t%s\t%s\t%d/%d\t\n",
+				q.Index, q.Last, q.Variation, q.PercentChange,
+				valuation(q.PE), valuation(q.PB), valuation(q.DY), q.Advances, q.Declines)
+		}
+		w.Flush()
+	}
+}
+
+func init() {
+	indicesCmd.Flags().StringP(categoryFlagName, categoryFlagShort, "", categoryFlagDesc)
+	rootCmd.AddCommand(indicesCmd)
+
+	indexCmd.Flags().StringP(indexNameFlagName, indexNameFlagShort, indexNameFlagDefault, indexNameFlagDescription)
+	indexCmd.Flags().String(sortFlagName, sortFlagDefault, "Sort constituents by one of "+sortKeys())
+	indexCmd.Flags().Bool(reverseFlagName, false, reverseFlagDesc)
+	rootCmd.AddCommand(indexCmd)
+}
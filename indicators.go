@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"nse/lib/nse"
+	"nse/lib/nse/indicators"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	indicatorsCmdUse   = "indicators"
+	indicatorsCmdShort = "Compute technical indicators over the daily history of a symbol"
+	smaFlagName        = "sma"
+	emaFlagName        = "ema"
+	rsiFlagName        = "rsi"
+	macdFlagName       = "macd"
+	macdFlagDefault    = "12,26,9"
+	bollingerFlagName  = "bollinger"
+	atrFlagName        = "atr"
+	supertrendFlagName = "supertrend"
+	stochasticFlagName = "stochastic"
+	obvFlagName        = "obv"
+	bollingerWidth     = 2
+	supertrendFactor   = 3
+	stochasticSmooth   = 3
+)
+
+// column is a column of the indicators table
+type column struct {
+	name  string
+	value func(i int) string
+}
+
+// floatColumn formats values with 2 decimals, and "-" while warming up
+func floatColumn(name string, values []float64) column {
+	return column{name: name, value: func(i int) string {
+		if math.IsNaN(values[i]) {
+			return "-"
+		}
+		return strconv.FormatFloat(values[i], 'f', 2, 64)
+	}}
+}
+
+var indicatorsCmd = &cobra.Command{
+	Use:   indicatorsCmdUse,
+	Short: indicatorsCmdShort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		symbol, _ := cmd.Flags().GetString(symbolFlagName)
+		candles, failed, err := historyFlags(cmd, symbol)
+		if err != nil {
+			return err
+		}
+		columns, err := indicatorColumns(cmd, candles)
+		if err != nil {
+			return err
+		}
+		printIndicators(candles, columns)
+		return gapsError(symbol, failed)
+	},
+}
+
+// indicatorColumns computes the indicators requested by the flags of cmd
+func indicatorColumns(cmd *cobra.Command, candles nse.Candles) ([]column, error) {
+	flags := cmd.Flags()
+	closes := candles.Closes()
+	var columns []column
+	if period, _ := flags.GetInt(smaFlagName); period > 0 {
+		columns = append(columns, floatColumn(fmt.Sprintf("SMA(%d)", period), indicators.SMASeries(closes, period)))
+	}
+	if period, _ := flags.GetInt(emaFlagName); period > 0 {
+		columns = append(columns, floatColumn(fmt.Sprintf("EMA(%d)", period), indicators.EMASeries(closes, period)))
+	}
+	if period, _ := flags.GetInt(rsiFlagName); period > 0 {
+		columns = append(columns, floatColumn(fmt.Sprintf("RSI(%d)", period), indicators.RSISeries(closes, period)))
+	}
+	if value, _ := flags.GetString(macdFlagName); value != "" {
+		periods, err := parsePeriods(value, 3)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", macdFlagName, err)
+		}
+		macd, signal, histogram := indicators.MACDSeries(closes, periods[0], periods[1], periods[2])
+		columns = append(columns, floatColumn("MACD", macd), floatColumn("Signal", signal), floatColumn("Histogram", histogram))
+	}
+	if period, _ := flags.GetInt(bollingerFlagName); period > 0 {
+		upper, middle, lower := indicators.BollingerSeries(closes, period, bollingerWidth)
+		columns = append(columns, floatColumn("BB upper", upper), floatColumn("BB middle", middle), floatColumn("BB lower", lower))
+	}
+	if period, _ := flags.GetInt(atrFlagName); period > 0 {
+		columns = append(columns, floatColumn(fmt.Sprintf("ATR(%d)", period), indicators.ATRSeries(candles, period)))
+	}
+	if period, _ := flags.GetInt(supertrendFlagName); period > 0 {
+		values, up := indicators.SupertrendSeries(candles, period, supertrendFactor)
+		columns = append(columns, floatColumn(fmt.Sprintf("Supertrend(%d)", period), values), column{name: "Trend", value: func(i int) string {
+			switch {
+			case math.IsNaN(values[i]):
+				return "-"
+			case up[i]:
+				return "up"
+			}
+			return "down"
+		}})
+	}
+	if period, _ := flags.GetInt(stochasticFlagName); period > 0 {
+		k, d := indicators.StochasticSeries(candles, period, stochasticSmooth, stochasticSmooth)
+		columns = append(columns, floatColumn("%K", k), floatColumn("%D", d))
+	}
+	if enabled, _ := flags.GetBool(obvFlagName); enabled {
+		obv := indicators.OBVSeries(candles)
+		columns = append(columns, column{name: "OBV", value: func(i int) string {
+			return strconv.FormatFloat(obv[i], 'f', 0, 64)
+		}})
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no indicator given, e.g. --%s 14", rsiFlagName)
+	}
+	return columns, nil
+}
+
+// parsePeriods parses n comma separated periods, such as "12,26,9"
+func parsePeriods(value string, n int) ([]int, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != n {
+		return nil, fmt.Errorf("want %d periods, got %q", n, value)
+	}
+	periods := make([]int, n)
+	for i, f := range fields {
+		p, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || p < 1 {
+			return nil, fmt.Errorf("invalid period %q", f)
+		}
+		periods[i] = p
+	}
+	return periods, nil
+}
+
+func printIndicators(candles nse.Candles, columns []column) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "Date\tClose\t")
+	for _, c := range columns {
+		fmt.Fprintf(w, "%s\t", c.name)
+	}
+	fmt.Fprintln(w)
+	for i, candle := range candles {
+		fmt.Fprintf(w, "%s\t%.2f\t", candle.Time.Format(dateFlagFormat), candle.Close)
+		for _, c := range columns {
+			fmt.Fprintf(w, "%s\t", c.value(i))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+}
+
+func init() {
+	flags := indicatorsCmd.Flags()
+	flags.StringP(symbolFlagName, symbolFlagShort, symbolFlagDefault, symbolFlagDescription)
+	flags.String(fromFlagName, "", fromFlagDescription)
+	flags.String(toFlagName, "", toFlagDescription)
+	flags.StringP(intervalFlagName, intervalFlagShort, intervalFlagDefault, intervalFlagDescription)
+	flags.Int(parallelFlagName, parallelFlagDefault, parallelFlagDescription)
+	flags.Int(retriesFlagName, retriesFlagDefault, retriesFlagDescription)
+
+	flags.Int(smaFlagName, 0, "Simple moving average over this many bars")
+	flags.Int(emaFlagName, 0, "Exponential moving average over this many bars")
+	flags.Int(rsiFlagName, 0, "Relative strength index over this many bars")
+	flags.String(macdFlagName, "", "MACD with these fast, slow and signal periods")
+	flags.Lookup(macdFlagName).NoOptDefVal = macdFlagDefault
+	flags.Int(bollingerFlagName, 0, "Bollinger Bands, 2 standard deviations wide, over this many bars")
+	flags.Int(atrFlagName, 0, "Average true range over this many bars")
+	flags.Int(supertrendFlagName, 0, "Supertrend with a multiplier of 3 over an ATR of this many bars")
+	flags.Int(stochasticFlagName, 0, "Stochastic oscillator, 3 bar slow %K and %D, over this many bars")
+	flags.Bool(obvFlagName, false, "On-balance volume")
+
+	rootCmd.AddCommand(indicatorsCmd)
+}
@@ -0,0 +1,229 @@
+package nse
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventKind is the kind of a corporate event
+type EventKind int
+
+const (
+	Dividend EventKind = iota + 1
+	Split
+	Bonus
+	Rights
+)
+
+var eventKindNames = [...]string{"", "dividend", "split", "bonus", "rights"}
+
+func (k EventKind) String() string {
+	if k <= 0 || int(k) >= len(eventKindNames) {
+		return "EventKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return eventKindNames[k]
+}
+
+// CorporateEvent is a corporate action that changes the price of a share
+type CorporateEvent struct {
+	Kind EventKind
+	// ExDate is midnight IST of the first trading day without the benefit
+	ExDate time.Time
+	// Ratio is the number of shares held after the event per share held
+	// before it for splits and bonus issues, which is below 1 for a
+	// consolidation, and the number of shares offered per share held for
+	// rights issues
+	Ratio float64
+	// Amount is the dividend per share, or the issue price of a rights share;
+	// 0 when the subject does not give it
+	Amount float64
+	// Subject is the text the event was parsed from
+	Subject string
+}
+
+var (
+	// amountPattern matches a rupee amount such as "Rs 10/-", "Re. 0.50" or "Rs - 1,200"
+	amountPattern = `(?:rs|re|inr|₹)\.?\s*-?\s*(\d[\d,]*(?:\.\d+)?|\.\d+)`
+
+	dividendPattern = regexp.MustCompile(`(?i)dividend\s*(?:of)?\s*[-:]?\s*(?:` + amountPattern + `|(\d+(?:\.\d+)?)\s*%)`)
+	splitPattern    = regexp.MustCompile(`(?i)(?:split|sub-?division|consolidation)\D*?from\s*` + amountPattern + `\D*?to\s*` + amountPattern)
+	bonusPattern    = regexp.MustCompile(`(?i)bonus\D*?(\d+)\s*:\s*(\d+)`)
+	rightsPattern   = regexp.MustCompile(`(?i)rights\D*?(\d+)\s*:\s*(\d+)(?:\s*@\s*(premium\s*(?:of\s*)?)?` + amountPattern + `)?`)
+)
+
+// ParseCorporateAction returns the events in the subject of a corporate
+// action, such as "Bonus 1:1", "Face Value Split (Sub-Division) - From Rs
+// 10/- Per Share To Rs 2/- Per Share", "Interim Dividend - Rs 5 Per Share" or
+// "Rights 1:5 @ Premium Rs 10/-". One subject may hold several dividends.
+// faceValue is used for dividends given as a percentage and for rights issued
+// at a premium, and may be 0 when unknown. The events have no ExDate; actions
+// that do not change the price, such as meetings, give none.
+func ParseCorporateAction(subject string, faceValue float64) []CorporateEvent {
+	var events []CorporateEvent
+	add := func(kind EventKind, ratio, amount float64) {
+		events = append(events, CorporateEvent{Kind: kind, Ratio: ratio, Amount: amount, Subject: subject})
+	}
+
+	for _, m := range dividendPattern.FindAllStringSubmatch(subject, -1) {
+		switch {
+		case m[1] != "":
+			add(Dividend, 0, parseAmount(m[1]))
+		case faceValue > 0:
+			add(Dividend, 0, parseAmount(m[2])*faceValue/100)
+		}
+	}
+	if m := splitPattern.FindStringSubmatch(subject); m != nil {
+		from, to := parseAmount(m[1]), parseAmount(m[2])
+		if from > 0 && to > 0 {
+			add(Split, from/to, 0)
+		}
+	}
+	if m := bonusPattern.FindStringSubmatch(subject); m != nil {
+		bonus, held := parseAmount(m[1]), parseAmount(m[2])
+		if bonus > 0 && held > 0 {
+			add(Bonus, (bonus+held)/held, 0)
+		}
+	}
+	if m := rightsPattern.FindStringSubmatch(subject); m != nil {
+		offered, held := parseAmount(m[1]), parseAmount(m[2])
+		if offered > 0 && held > 0 {
+			price := parseAmount(m[4])
+			if m[3] != "" && price > 0 {
+				price += faceValue
+			}
+			add(Rights, offered/held, price)
+		}
+	}
+	return events
+}
+
+// parseAmount parses a number with thousands separators, returning 0 when
+// there is none
+func parseAmount(s string) float64 {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// Events returns the events of the action on its ex-date
+func (a CorporateAction) Events() []CorporateEvent {
+	if a.ExDate.IsZero() {
+		return nil
+	}
+	events := ParseCorporateAction(a.Subject, float64(a.FaceVal))
+	exDate := a.ExDate.In(IST)
+	exDate = time.Date(exDate.Year(), exDate.Month(), exDate.Day(), 0, 0, 0, 0, IST)
+	for i := range events {
+		events[i].ExDate = exDate
+	}
+	return events
+}
+
+// CorporateEvents returns the events of actions in ex-date order. Actions
+// without an ex-date are skipped, as are events listed more than once, e.g.
+// for several series.
+func CorporateEvents(actions []CorporateAction) []CorporateEvent {
+	type key struct {
+		kind          EventKind
+		exDate        int64
+		ratio, amount float64
+	}
+	seen := make(map[key]bool)
+	var events []CorporateEvent
+	for _, a := range actions {
+		for _, e := range a.Events() {
+			k := key{e.Kind, e.ExDate.Unix(), e.Ratio, e.Amount}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ExDate.Before(events[j].ExDate) })
+	return events
+}
+
+// Adjustment selects the events AdjustedHistory adjusts for
+type Adjustment int
+
+const (
+	// AdjustSplits adjusts for splits, consolidations, bonus and rights
+	// issues, which change the number of shares
+	AdjustSplits Adjustment = iota
+	// AdjustTotalReturn also adjusts for dividends, as if they were
+	// reinvested on the ex-date
+	AdjustTotalReturn
+)
+
+// AdjustedHistory back-adjusts daily candles for events, so the prices before
+// each ex-date are comparable with those after it. Prices are multiplied, and
+// volumes divided, by the factor of every later event; the latest prices are
+// unchanged. The factor of a split or bonus issue is 1/Ratio. Rights issues
+// and dividends use the close before the ex-date: a rights issue adjusts to
+// the theoretical ex-rights price, and is skipped when its issue price is
+// unknown or not below that close; a dividend adjusts by (close-Amount)/close
+// and leaves volumes unchanged. The candles are not modified.
+func AdjustedHistory(candles Candles, events []CorporateEvent, mode Adjustment) Candles {
+	type factor struct {
+		exDate        time.Time
+		price, shares float64
+	}
+	var factors []factor
+	for _, e := range events {
+		// the last candle before the ex-date
+		i := sort.Search(len(candles), func(i int) bool { return !candles[i].Time.Before(e.ExDate) }) - 1
+		if i < 0 {
+			continue
+		}
+		cum := candles[i].Close
+		f := factor{exDate: e.ExDate, price: 1, shares: 1}
+		switch e.Kind {
+		case Split, Bonus:
+			if e.Ratio > 0 {
+				f.price, f.shares = 1/e.Ratio, e.Ratio
+			}
+		case Rights:
+			if e.Ratio > 0 && e.Amount > 0 && e.Amount < cum {
+				exRights := (cum + e.Ratio*e.Amount) / (1 + e.Ratio)
+				f.price = exRights / cum
+				f.shares = 1 / f.price
+			}
+		case Dividend:
+			if mode == AdjustTotalReturn && e.Amount > 0 && e.Amount < cum {
+				f.price = (cum - e.Amount) / cum
+			}
+		}
+		if f.price != 1 || f.shares != 1 {
+			factors = append(factors, f)
+		}
+	}
+	sort.SliceStable(factors, func(i, j int) bool { return factors[i].exDate.After(factors[j].exDate) })
+
+	adjusted := make(Candles, len(candles))
+	price, shares := 1.0, 1.0
+	next := 0
+	for i := len(candles) - 1; i >= 0; i-- {
+		c := candles[i]
+		for next < len(factors) && c.Time.Before(factors[next].exDate) {
+			price *= factors[next].price
+			shares *= factors[next].shares
+			next++
+		}
+		c.Open *= price
+		c.High *= price
+		c.Low *= price
+		c.Close *= price
+		c.PrevClose *= price
+		c.VWAP *= price
+		c.Volume = int64(math.Round(float64(c.Volume) * shares))
+		adjusted[i] = c
+	}
+	return adjusted
+}
@@ -0,0 +1,100 @@
+package nse
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCorporateAction(t *testing.T) {
+	tests := []struct {
+		subject   string
+		faceValue float64
+		want      []CorporateEvent
+	}{
+		{"Bonus 1:1", 10, []CorporateEvent{{Kind: Bonus, Ratio: 2}}},
+		{"Bonus 3:2", 10, []CorporateEvent{{Kind: Bonus, Ratio: 2.5}}},
+		{"Face Value Split From Rs 10 To Rs 2", 10, []CorporateEvent{{Kind: Split, Ratio: 5}}},
+		{"Face Value Split (Sub-Division) - From Rs 10/- Per Share To Re 1/- Per Share", 10, []CorporateEvent{{Kind: Split, Ratio: 10}}},
+		{"Consolidation Of Shares From Re 1/- To Rs 10/-", 1, []CorporateEvent{{Kind: Split, Ratio: 0.1}}},
+		{"Interim Dividend - Rs 5 Per Share", 1, []CorporateEvent{{Kind: Dividend, Amount: 5}}},
+		{"Annual General Meeting/Dividend - Re 0.50 Per Share", 1, []CorporateEvent{{Kind: Dividend, Amount: 0.5}}},
+		{"Final Dividend - Rs. - 1,200 Per Share", 10, []CorporateEvent{{Kind: Dividend, Amount: 1200}}},
+		{"Dividend - 40%", 5, []CorporateEvent{{Kind: Dividend, Amount: 2}}},
+		{"Dividend - 40%", 0, nil},
+		{"Dividend - Rs 8 Per Share And Special Dividend - Rs 5 Per Share", 1, []CorporateEvent{
+			{Kind: Dividend, Amount: 8}, {Kind: Dividend, Amount: 5}}},
+		{"Rights 1:5 @ Premium Rs 90/-", 10, []CorporateEvent{{Kind: Rights, Ratio: 0.2, Amount: 100}}},
+		{"Rights 3:20 @ Rs 1,200", 10, []CorporateEvent{{Kind: Rights, Ratio: 0.15, Amount: 1200}}},
+		{"Rights 1:4", 10, []CorporateEvent{{Kind: Rights, Ratio: 0.25}}},
+		{"Annual General Meeting", 10, nil},
+		{"Demerger", 10, nil},
+	}
+	for _, tt := range tests {
+		for i := range tt.want {
+			tt.want[i].Subject = tt.subject
+		}
+		got := ParseCorporateAction(tt.subject, tt.faceValue)
+		require.Len(t, got, len(tt.want), tt.subject)
+		for i := range got {
+			assert.Equal(t, tt.want[i].Kind, got[i].Kind, tt.subject)
+			assert.InDelta(t, tt.want[i].Ratio, got[i].Ratio, 1e-9, tt.subject)
+			assert.InDelta(t, tt.want[i].Amount, got[i].Amount, 1e-9, tt.subject)
+			assert.Equal(t, tt.subject, got[i].Subject)
+		}
+	}
+}
+
+func TestCorporateEvents(t *testing.T) {
+	var actions []CorporateAction
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"series":"EQ","faceVal":"10","subject":"Dividend - Rs 2 Per Share","exDate":"10-Jul-2024"},
+		{"series":"EQ","faceVal":"10","subject":"Bonus 1:1","exDate":"15-Mar-2024"},
+		{"series":"BE","faceVal":"10","subject":"Bonus 1:1","exDate":"15-Mar-2024"},
+		{"series":"EQ","faceVal":"10","subject":"Annual General Meeting","exDate":"10-Jul-2024"},
+		{"series":"EQ","faceVal":"10","subject":"Bonus 2:1","exDate":"-"}]`), &actions))
+
+	events := CorporateEvents(actions)
+	require.Len(t, events, 2)
+	assert.Equal(t, Bonus, events[0].Kind)
+	assert.True(t, events[0].ExDate.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, IST)))
+	assert.Equal(t, Dividend, events[1].Kind)
+	assert.Equal(t, "dividend", events[1].Kind.String())
+}
+
+func TestAdjustedHistory(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, IST) }
+	candles := Candles{
+		{Time: day(11), Open: 200, High: 210, Low: 190, Close: 200, PrevClose: 198, Volume: 100, VWAP: 201},
+		{Time: day(12), Open: 200, High: 204, Low: 196, Close: 200, PrevClose: 200, Volume: 100},
+		{Time: day(13), Open: 100, High: 102, Low: 98, Close: 100, PrevClose: 100, Volume: 250},
+		{Time: day(14), Open: 96, High: 97, Low: 95, Close: 96, PrevClose: 100, Volume: 300},
+	}
+	events := []CorporateEvent{
+		{Kind: Dividend, ExDate: day(14), Amount: 4},
+		{Kind: Bonus, ExDate: day(13), Ratio: 2},
+		{Kind: Split, ExDate: day(1), Ratio: 5},
+	}
+
+	splits := AdjustedHistory(candles, events, AdjustSplits)
+	assert.Equal(t, []float64{100, 100, 100, 96}, splits.Closes())
+	assert.Equal(t, Candle{Time: day(11), Open: 100, High: 105, Low: 95, Close: 100, PrevClose: 99, Volume: 200, VWAP: 100.5}, splits[0])
+	assert.Equal(t, int64(250), splits[2].Volume)
+	assert.Equal(t, candles[3], splits[3])
+	assert.Equal(t, 200.0, candles[0].Close, "input is not modified")
+
+	total := AdjustedHistory(candles, events, AdjustTotalReturn)
+	assert.InDeltaSlice(t, []float64{96, 96, 96, 96}, total.Closes(), 1e-9)
+	assert.Equal(t, int64(200), total[0].Volume)
+
+	rights := AdjustedHistory(candles[2:], []CorporateEvent{{Kind: Rights, ExDate: day(14), Ratio: 0.25, Amount: 80}}, AdjustSplits)
+	// ex-rights price (100 + 0.25*80) / 1.25 = 96
+	assert.InDelta(t, 96, rights[0].Close, 1e-9)
+	assert.Equal(t, int64(260), rights[0].Volume)
+
+	unknown := AdjustedHistory(candles, []CorporateEvent{{Kind: Rights, ExDate: day(14), Ratio: 0.25}}, AdjustSplits)
+	assert.Equal(t, candles, unknown)
+}
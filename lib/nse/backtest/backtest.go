@@ -0,0 +1,169 @@
+// Package backtest runs trading strategies over daily candles, such as those
+// of EquityHytoricalData.Candles or of a store.Store, with a simulated broker
+// that charges Indian trading costs.
+//
+// On every bar Run first fills the orders placed on the previous bar for the
+// next open, then calls the strategy with the bar, then fills the orders
+// placed for its close, and finally records the equity at the close:
+//
+//	result, err := backtest.Run(history.Candles(), backtest.StrategyFunc(func(b *backtest.Broker, c nse.Candle) {
+//		if b.Position() == 0 && c.Close > c.PrevClose {
+//			b.Buy(10)
+//		}
+//	}), backtest.Config{Cash: 100000, Costs: backtest.NSEDelivery()})
+package backtest
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"nse/lib/nse"
+)
+
+// Strategy decides the orders to place on every bar
+type Strategy interface {
+	// OnBar is called with every bar, once it is complete
+	OnBar(b *Broker, candle nse.Candle)
+}
+
+// StrategyFunc adapts a function to a Strategy
+type StrategyFunc func(b *Broker, candle nse.Candle)
+
+// OnBar implements Strategy
+func (f StrategyFunc) OnBar(b *Broker, candle nse.Candle) {
+	f(b, candle)
+}
+
+// defaultPeriodsPerYear is the number of trading days in a year
+const defaultPeriodsPerYear = 252
+
+// Config configures a backtest
+type Config struct {
+	// Cash is the starting capital
+	Cash float64
+	// Costs prices the fills; nil charges nothing
+	Costs CostModel
+	// CircuitLimit is the price band of the security as a fraction of the
+	// previous close, e.g. 0.2 for 20%. Buys do not fill on bars locked at the
+	// upper band, nor sells on bars locked at the lower band. 0 ignores bands.
+	CircuitLimit float64
+	// RiskFreeRate is the yearly return Sharpe is measured against
+	RiskFreeRate float64
+	// PeriodsPerYear is the number of bars in a year, 252 by default; set it
+	// to 52 for weekly candles
+	PeriodsPerYear int
+}
+
+// EquityPoint is the value of the account at the close of a bar
+type EquityPoint struct {
+	Time   time.Time
+	Equity float64
+}
+
+// Result is the outcome of a backtest
+type Result struct {
+	Equity []EquityPoint
+	Trades []Trade
+	// Expired are the orders that did not fill
+	Expired []Order
+	// Final is the equity at the last close
+	Final float64
+	// CAGR is the compound yearly growth from the starting cash to Final
+	CAGR float64
+	// MaxDrawdown is the largest fall of the equity from a previous high, as
+	// a fraction of that high
+	MaxDrawdown float64
+	// Sharpe is the yearly Sharpe ratio of the returns per bar
+	Sharpe float64
+	// Charges is the total of the charges of all trades
+	Charges float64
+}
+
+// ErrNoCandles is returned by Run when there is nothing to test on
+var ErrNoCandles = errors.New("backtest: no candles")
+
+// Run tests strategy over candles, which must be in time order
+func Run(candles nse.Candles, strategy Strategy, cfg Config) (*Result, error) {
+	if len(candles) == 0 {
+		return nil, ErrNoCandles
+	}
+	if cfg.Cash <= 0 {
+		return nil, errors.New("backtest: no starting cash")
+	}
+	if cfg.PeriodsPerYear <= 0 {
+		cfg.PeriodsPerYear = defaultPeriodsPerYear
+	}
+
+	b := &Broker{cash: cfg.Cash, costs: cfg.Costs, circuit: cfg.CircuitLimit}
+	result := &Result{Equity: make([]EquityPoint, 0, len(candles))}
+	for _, c := range candles {
+		b.open(c)
+		strategy.OnBar(b, c)
+		b.close()
+		result.Equity = append(result.Equity, EquityPoint{Time: c.Time, Equity: b.Equity()})
+	}
+
+	result.Trades, result.Expired = b.trades, b.expired
+	for _, t := range b.trades {
+		result.Charges += t.Charges.Total()
+	}
+	result.Final = result.Equity[len(result.Equity)-1].Equity
+	result.CAGR = cagr(cfg.Cash, result.Final, candles[0].Time, candles[len(candles)-1].Time)
+	result.MaxDrawdown = maxDrawdown(cfg.Cash, result.Equity)
+	result.Sharpe = sharpe(cfg.Cash, result.Equity, cfg.RiskFreeRate, cfg.PeriodsPerYear)
+	return result, nil
+}
+
+// cagr returns the compound yearly growth from start to end over the time
+// from first to last, or 0 if that is not positive
+func cagr(start, end float64, first, last time.Time) float64 {
+	years := last.Sub(first).Hours() / 24 / 365.25
+	if years <= 0 || start <= 0 || end <= 0 {
+		return 0
+	}
+	return math.Pow(end/start, 1/years) - 1
+}
+
+// maxDrawdown returns the largest fall of equity from a previous high,
+// starting from cash
+func maxDrawdown(cash float64, equity []EquityPoint) float64 {
+	peak, drawdown := cash, 0.0
+	for _, p := range equity {
+		peak = max(peak, p.Equity)
+		if peak > 0 {
+			drawdown = max(drawdown, (peak-p.Equity)/peak)
+		}
+	}
+	return drawdown
+}
+
+// sharpe returns the yearly Sharpe ratio of the returns of equity, the first
+// of them from cash, using the sample standard deviation
+func sharpe(cash float64, equity []EquityPoint, riskFree float64, periods int) float64 {
+	excess := make([]float64, 0, len(equity))
+	prev := cash
+	for _, p := range equity {
+		if prev > 0 {
+			excess = append(excess, p.Equity/prev-1-riskFree/float64(periods))
+		}
+		prev = p.Equity
+	}
+	if len(excess) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, r := range excess {
+		mean += r
+	}
+	mean /= float64(len(excess))
+	var variance float64
+	for _, r := range excess {
+		variance += (r - mean) * (r - mean)
+	}
+	sd := math.Sqrt(variance / float64(len(excess)-1))
+	if sd == 0 {
+		return 0
+	}
+	return mean / sd * math.Sqrt(float64(periods))
+}
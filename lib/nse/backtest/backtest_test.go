@@ -0,0 +1,78 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nse/lib/nse"
+	"nse/lib/nse/indicators"
+)
+
+func TestRunErrors(t *testing.T) {
+	_, err := Run(nil, StrategyFunc(func(*Broker, nse.Candle) {}), Config{Cash: 1})
+	assert.ErrorIs(t, err, ErrNoCandles)
+	_, err = Run(bars([2]float64{1, 1}), StrategyFunc(func(*Broker, nse.Candle) {}), Config{})
+	assert.Error(t, err)
+}
+
+func TestMetrics(t *testing.T) {
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, nse.IST)
+	assert.InDelta(t, 0.1, cagr(100, 121, first, first.Add(2*365.25*24*time.Hour)), 1e-9)
+	assert.Zero(t, cagr(100, 121, first, first))
+
+	equity := []EquityPoint{{Equity: 110}, {Equity: 99}, {Equity: 120}, {Equity: 90}, {Equity: 130}}
+	assert.InDelta(t, 0.25, maxDrawdown(100, equity), 1e-9)
+
+	// returns of 1% and 3% have a mean of 2% and a deviation of sqrt(2)%
+	equity = []EquityPoint{{Equity: 101}, {Equity: 104.03}}
+	assert.InDelta(t, 2/math.Sqrt2*math.Sqrt(252), sharpe(100, equity, 0, 252), 1e-9)
+	assert.InDelta(t, (2-1)/math.Sqrt2*math.Sqrt(252), sharpe(100, equity, 0.01*252, 252), 1e-9)
+	assert.Zero(t, sharpe(100, []EquityPoint{{Equity: 101}, {Equity: 102.01}}, 0, 252))
+}
+
+// smaCross holds shares while the close is above its moving average
+type smaCross struct {
+	sma *indicators.SMA
+	qty int64
+}
+
+func (s *smaCross) OnBar(b *Broker, c nse.Candle) {
+	avg, ok := s.sma.Update(c.Close)
+	switch {
+	case !ok:
+	case c.Close > avg && b.Position() == 0:
+		b.Buy(s.qty)
+	case c.Close < avg && b.Position() > 0:
+		b.Sell(b.Position())
+	}
+}
+
+func TestRunStrategy(t *testing.T) {
+	var candles nse.Candles
+	for i := 0; i < 60; i++ {
+		// two rallies with a fall between them
+		price := 100 + 20*math.Sin(float64(i)/6)
+		candles = append(candles, nse.Candle{
+			Time: time.Date(2024, 1, 1, 0, 0, 0, 0, nse.IST).AddDate(0, 0, i),
+			Open: price, High: price + 1, Low: price - 1, Close: price,
+		})
+	}
+
+	result, err := Run(candles, &smaCross{sma: indicators.NewSMA(5), qty: 100}, Config{Cash: 100000, Costs: NSEDelivery()})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Trades)
+	assert.Len(t, result.Equity, len(candles))
+	for i, trade := range result.Trades {
+		assert.Equal(t, []Side{Buy, Sell}[i%2], trade.Side, "trade %d", i)
+		assert.Positive(t, trade.Charges.Total())
+	}
+	assert.Greater(t, result.Final, 100000.0)
+	assert.Greater(t, result.CAGR, 0.0)
+	assert.Greater(t, result.Sharpe, 0.0)
+	assert.Greater(t, result.MaxDrawdown, 0.0)
+	assert.Less(t, result.MaxDrawdown, 0.1)
+}
@@ -0,0 +1,279 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"nse/lib/nse"
+)
+
+// Side is the side of an order
+type Side int
+
+const (
+	Buy Side = iota + 1
+	Sell
+)
+
+func (s Side) String() string {
+	switch s {
+	case Buy:
+		return "buy"
+	case Sell:
+		return "sell"
+	}
+	return fmt.Sprintf("Side(%d)", int(s))
+}
+
+// OrderType is the type of an order
+type OrderType int
+
+const (
+	// Market orders fill at the price of the bar they fill on
+	Market OrderType = iota
+	// Limit orders fill at their limit price or better, if the bar reaches it
+	Limit
+)
+
+// FillAt is the price an order fills at
+type FillAt int
+
+const (
+	// NextOpen orders fill on the bar after the one they were placed on: market
+	// orders at its open, limit orders at its open or their limit price
+	NextOpen FillAt = iota
+	// OnClose orders fill at the close of the bar they were placed on
+	OnClose
+)
+
+// circuitTolerance is how close to a price band a bar must be to be locked
+// at it, allowing for the band being rounded to the 0.05 tick
+const circuitTolerance = 0.05
+
+// Order is an order to the simulated broker. Orders are day orders: those
+// that do not fill on their bar expire.
+type Order struct {
+	// ID is set by Broker.Submit
+	ID    int
+	Side  Side
+	Type  OrderType
+	Qty   int64
+	Limit float64
+	At    FillAt
+	// Placed is the time of the bar the order was placed on
+	Placed time.Time
+}
+
+// Trade is a fill of an order
+type Trade struct {
+	OrderID int
+	Time    time.Time
+	Side    Side
+	Qty     int64
+	Price   float64
+	Charges Charges
+	// PnL is the profit of a sell over the average cost of the shares sold,
+	// after the charges of buying and selling them; 0 for buys
+	PnL float64
+}
+
+// Broker is the simulated broker a Strategy places orders with. It holds a
+// long-only position: sells are limited to the shares held, and buys to the
+// shares the cash pays for, charges included.
+type Broker struct {
+	cash    float64
+	qty     int64
+	cost    float64
+	costs   CostModel
+	circuit float64
+
+	bar     nse.Candle
+	nextID  int
+	pending []Order
+	trades  []Trade
+	expired []Order
+}
+
+// Cash returns the cash not invested
+func (b *Broker) Cash() float64 {
+	return b.cash
+}
+
+// Position returns the number of shares held
+func (b *Broker) Position() int64 {
+	return b.qty
+}
+
+// AvgPrice returns the average cost of the shares held, buy charges included
+func (b *Broker) AvgPrice() float64 {
+	if b.qty == 0 {
+		return 0
+	}
+	return b.cost / float64(b.qty)
+}
+
+// Equity returns the cash and the position valued at the close of the
+// current bar
+func (b *Broker) Equity() float64 {
+	return b.cash + float64(b.qty)*b.bar.Close
+}
+
+// Submit places o on the current bar and returns its ID
+func (b *Broker) Submit(o Order) int {
+	b.nextID++
+	o.ID, o.Placed = b.nextID, b.bar.Time
+	b.pending = append(b.pending, o)
+	return o.ID
+}
+
+// Buy places a market order for qty shares at the next open
+func (b *Broker) Buy(qty int64) int {
+	return b.Submit(Order{Side: Buy, Qty: qty})
+}
+
+// Sell places a market order for qty shares at the next open
+func (b *Broker) Sell(qty int64) int {
+	return b.Submit(Order{Side: Sell, Qty: qty})
+}
+
+// Pending returns the orders not filled yet
+func (b *Broker) Pending() []Order {
+	return append([]Order(nil), b.pending...)
+}
+
+// open starts bar, filling or expiring the NextOpen orders of the previous bar
+func (b *Broker) open(bar nse.Candle) {
+	b.bar = bar
+	b.execute(NextOpen)
+}
+
+// close fills or expires the OnClose orders of the current bar
+func (b *Broker) close() {
+	b.execute(OnClose)
+}
+
+// execute fills or expires the pending orders filling at at, in the order
+// they were placed
+func (b *Broker) execute(at FillAt) {
+	remaining := b.pending[:0]
+	var due []Order
+	for _, o := range b.pending {
+		if o.At == at {
+			due = append(due, o)
+		} else {
+			remaining = append(remaining, o)
+		}
+	}
+	b.pending = remaining
+	for _, o := range due {
+		if !b.fill(o) {
+			b.expired = append(b.expired, o)
+		}
+	}
+}
+
+// fill fills o on the current bar, reporting false when it cannot
+func (b *Broker) fill(o Order) bool {
+	price, ok := b.price(o)
+	if !ok || b.locked(o.Side) {
+		return false
+	}
+
+	qty := o.Qty
+	if o.Side == Sell {
+		qty = min(qty, b.qty)
+	} else {
+		qty = b.affordable(qty, price)
+	}
+	if qty <= 0 {
+		return false
+	}
+
+	charges := b.charges(o.Side, qty, price)
+	trade := Trade{OrderID: o.ID, Time: b.bar.Time, Side: o.Side, Qty: qty, Price: price, Charges: charges}
+	value := float64(qty) * price
+	if o.Side == Buy {
+		b.cash -= value + charges.Total()
+		b.cost += value + charges.Total()
+		b.qty += qty
+	} else {
+		cost := b.cost * float64(qty) / float64(b.qty)
+		trade.PnL = value - charges.Total() - cost
+		b.cash += value - charges.Total()
+		b.cost -= cost
+		b.qty -= qty
+	}
+	b.trades = append(b.trades, trade)
+	return true
+}
+
+// price returns the price o fills at on the current bar, or false if the
+// bar does not reach its limit
+func (b *Broker) price(o Order) (float64, bool) {
+	bar := b.bar
+	if o.At == OnClose {
+		switch {
+		case o.Type == Market:
+			return bar.Close, true
+		case o.Side == Buy:
+			return bar.Close, bar.Close <= o.Limit
+		default:
+			return bar.Close, bar.Close >= o.Limit
+		}
+	}
+	switch {
+	case o.Type == Market:
+		return bar.Open, true
+	case o.Side == Buy:
+		return min(bar.Open, o.Limit), bar.Low <= o.Limit
+	default:
+		return max(bar.Open, o.Limit), bar.High >= o.Limit
+	}
+}
+
+// locked reports whether the current bar traded only at the price band that
+// leaves no other side for orders of side: the upper circuit for buys, the
+// lower circuit for sells
+func (b *Broker) locked(side Side) bool {
+	if b.circuit <= 0 || b.bar.PrevClose <= 0 {
+		return false
+	}
+	if side == Buy {
+		return b.bar.Low >= b.bar.PrevClose*(1+b.circuit)-circuitTolerance
+	}
+	return b.bar.High <= b.bar.PrevClose*(1-b.circuit)+circuitTolerance
+}
+
+// affordable returns the largest quantity up to qty the cash pays for at
+// price, charges included
+func (b *Broker) affordable(qty int64, price float64) int64 {
+	if price <= 0 {
+		return 0
+	}
+	fits := func(q int64) bool {
+		return float64(q)*price+b.charges(Buy, q, price).Total() <= b.cash
+	}
+	hi := min(qty, int64(math.Floor(b.cash/price)))
+	if hi <= 0 || fits(hi) {
+		return max(hi, 0)
+	}
+	// fits(lo) holds and fits(hi) does not
+	lo := int64(0)
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
+
+func (b *Broker) charges(side Side, qty int64, price float64) Charges {
+	if b.costs == nil {
+		return Charges{}
+	}
+	return b.costs.Charges(side, qty, price)
+}
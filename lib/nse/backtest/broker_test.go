@@ -0,0 +1,128 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nse/lib/nse"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, nse.IST)
+}
+
+// bars returns daily candles with the given opens and closes, and highs and
+// lows 5 above and below them
+func bars(prices ...[2]float64) nse.Candles {
+	var candles nse.Candles
+	prev := prices[0][0]
+	for i, p := range prices {
+		candles = append(candles, nse.Candle{
+			Time: day(i + 1), Open: p[0], Close: p[1], PrevClose: prev,
+			High: max(p[0], p[1]) + 5, Low: min(p[0], p[1]) - 5,
+		})
+		prev = p[1]
+	}
+	return candles
+}
+
+// script returns a strategy placing orders[i] on bar i
+func script(orders map[int][]Order) Strategy {
+	i := 0
+	return StrategyFunc(func(b *Broker, _ nse.Candle) {
+		for _, o := range orders[i] {
+			b.Submit(o)
+		}
+		i++
+	})
+}
+
+func TestMarketNextOpen(t *testing.T) {
+	candles := bars([2]float64{100, 102}, [2]float64{103, 106}, [2]float64{106, 110}, [2]float64{111, 108})
+	result, err := Run(candles, script(map[int][]Order{
+		0: {{Side: Buy, Qty: 10}},
+		2: {{Side: Sell, Qty: 50}},
+	}), Config{Cash: 10000})
+	require.NoError(t, err)
+
+	require.Len(t, result.Trades, 2)
+	assert.Equal(t, Trade{OrderID: 1, Time: day(2), Side: Buy, Qty: 10, Price: 103}, result.Trades[0])
+	// the sell is limited to the shares held
+	assert.Equal(t, Trade{OrderID: 2, Time: day(4), Side: Sell, Qty: 10, Price: 111, PnL: 80}, result.Trades[1])
+
+	assert.Equal(t, []EquityPoint{
+		{Time: day(1), Equity: 10000},
+		{Time: day(2), Equity: 10030},
+		{Time: day(3), Equity: 10070},
+		{Time: day(4), Equity: 10080},
+	}, result.Equity)
+	assert.Equal(t, 10080.0, result.Final)
+	assert.Empty(t, result.Expired)
+}
+
+func TestLimitAndCloseOrders(t *testing.T) {
+	candles := bars([2]float64{100, 100}, [2]float64{100, 96}, [2]float64{96, 98})
+	result, err := Run(candles, script(map[int][]Order{
+		0: {
+			{Side: Buy, Type: Limit, Qty: 5, Limit: 97},
+			{Side: Buy, Type: Limit, Qty: 5, Limit: 80},
+			{Side: Buy, Qty: 1, At: OnClose},
+		},
+		1: {{Side: Sell, Type: Limit, Qty: 6, Limit: 99, At: OnClose}},
+		2: {{Side: Sell, Type: Limit, Qty: 6, Limit: 97, At: OnClose}},
+	}), Config{Cash: 10000})
+	require.NoError(t, err)
+
+	require.Len(t, result.Trades, 3)
+	assert.Equal(t, Trade{OrderID: 3, Time: day(1), Side: Buy, Qty: 1, Price: 100}, result.Trades[0])
+	assert.Equal(t, Trade{OrderID: 1, Time: day(2), Side: Buy, Qty: 5, Price: 97}, result.Trades[1])
+	assert.Equal(t, Sell, result.Trades[2].Side)
+	assert.Equal(t, 98.0, result.Trades[2].Price)
+	assert.InDelta(t, 6*98-(100+5*97), result.Trades[2].PnL, 1e-9)
+
+	require.Len(t, result.Expired, 2)
+	assert.Equal(t, 80.0, result.Expired[0].Limit)
+	assert.Equal(t, 99.0, result.Expired[1].Limit)
+	assert.True(t, result.Expired[1].Placed.Equal(day(2)))
+}
+
+func TestCircuitLimit(t *testing.T) {
+	candles := nse.Candles{
+		{Time: day(1), Open: 100, High: 100, Low: 100, Close: 100, PrevClose: 100},
+		// locked at the 5% upper band
+		{Time: day(2), Open: 105, High: 105, Low: 105, Close: 105, PrevClose: 100},
+		{Time: day(3), Open: 106, High: 110, Low: 104, Close: 108, PrevClose: 105},
+	}
+	buyEveryBar := StrategyFunc(func(b *Broker, _ nse.Candle) {
+		if b.Position() == 0 {
+			b.Buy(1)
+		}
+	})
+
+	result, err := Run(candles, buyEveryBar, Config{Cash: 1000, CircuitLimit: 0.05})
+	require.NoError(t, err)
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, day(3), result.Trades[0].Time)
+	assert.Len(t, result.Expired, 1)
+
+	result, err = Run(candles, buyEveryBar, Config{Cash: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, day(2), result.Trades[0].Time)
+}
+
+func TestBuyLimitedByCash(t *testing.T) {
+	candles := bars([2]float64{100, 100}, [2]float64{100, 100})
+	result, err := Run(candles, script(map[int][]Order{0: {{Side: Buy, Qty: 1000}}}),
+		Config{Cash: 10000, Costs: NSEDelivery()})
+	require.NoError(t, err)
+
+	require.Len(t, result.Trades, 1)
+	// 100 shares cost 10000 before charges
+	assert.Equal(t, int64(99), result.Trades[0].Qty)
+	charges := result.Trades[0].Charges.Total()
+	assert.InDelta(t, 10000-charges, result.Equity[1].Equity, 1e-9)
+	assert.InDelta(t, charges, result.Charges, 1e-9)
+}
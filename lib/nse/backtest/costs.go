@@ -0,0 +1,79 @@
+package backtest
+
+// Charges are the costs of one fill, in rupees
+type Charges struct {
+	Brokerage float64
+	// STT is the securities transaction tax
+	STT float64
+	// Exchange is the exchange transaction charge
+	Exchange float64
+	// SEBI is the SEBI turnover fee
+	SEBI float64
+	// GST is levied on brokerage, exchange charges, SEBI fees and DP charges
+	GST       float64
+	StampDuty float64
+	// DP is the depository charge for debiting the shares sold
+	DP float64
+}
+
+// Total returns the sum of the charges
+func (c Charges) Total() float64 {
+	return c.Brokerage + c.STT + c.Exchange + c.SEBI + c.GST + c.StampDuty + c.DP
+}
+
+// CostModel prices the fills of the simulated broker
+type CostModel interface {
+	Charges(side Side, qty int64, price float64) Charges
+}
+
+// DeliveryCosts is the cost model of equity delivery trades. Rates are
+// fractions of the traded value; amounts are not rounded as on contract notes.
+type DeliveryCosts struct {
+	// BrokerageRate is charged on the traded value, up to BrokerageMax per
+	// fill when BrokerageMax is above 0
+	BrokerageRate float64
+	BrokerageMax  float64
+	// STTRate is charged on buys and sells
+	STTRate      float64
+	ExchangeRate float64
+	SEBIRate     float64
+	GSTRate      float64
+	// StampDutyRate is charged on buys only
+	StampDutyRate float64
+	// DPCharge is charged per sell
+	DPCharge float64
+}
+
+// NSEDelivery returns the statutory charges of NSE equity delivery trades,
+// with the DP charge of a typical discount broker and no brokerage
+func NSEDelivery() DeliveryCosts {
+	return DeliveryCosts{
+		STTRate:       0.001,
+		ExchangeRate:  0.0000297,
+		SEBIRate:      0.000001,
+		GSTRate:       0.18,
+		StampDutyRate: 0.00015,
+		DPCharge:      13.5,
+	}
+}
+
+// Charges implements CostModel
+func (d DeliveryCosts) Charges(side Side, qty int64, price float64) Charges {
+	value := float64(qty) * price
+	c := Charges{
+		Brokerage: value * d.BrokerageRate,
+		STT:       value * d.STTRate,
+		Exchange:  value * d.ExchangeRate,
+		SEBI:      value * d.SEBIRate,
+	}
+	if d.BrokerageMax > 0 {
+		c.Brokerage = min(c.Brokerage, d.BrokerageMax)
+	}
+	if side == Buy {
+		c.StampDuty = value * d.StampDutyRate
+	} else {
+		c.DP = d.DPCharge
+	}
+	c.GST = (c.Brokerage + c.Exchange + c.SEBI + c.DP) * d.GSTRate
+	return c
+}
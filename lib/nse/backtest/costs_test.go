@@ -0,0 +1,33 @@
+package backtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNSEDelivery(t *testing.T) {
+	costs := NSEDelivery()
+
+	buy := costs.Charges(Buy, 100, 1000)
+	assert.InDelta(t, 100, buy.STT, 1e-9)
+	assert.InDelta(t, 2.97, buy.Exchange, 1e-9)
+	assert.InDelta(t, 0.1, buy.SEBI, 1e-9)
+	assert.InDelta(t, 15, buy.StampDuty, 1e-9)
+	assert.Zero(t, buy.DP)
+	assert.InDelta(t, 0.5526, buy.GST, 1e-9)
+	assert.InDelta(t, 118.6226, buy.Total(), 1e-9)
+
+	sell := costs.Charges(Sell, 100, 1000)
+	assert.Zero(t, sell.StampDuty)
+	assert.InDelta(t, 13.5, sell.DP, 1e-9)
+	assert.InDelta(t, 2.9826, sell.GST, 1e-9)
+	assert.InDelta(t, 119.5526, sell.Total(), 1e-9)
+}
+
+func TestDeliveryBrokerage(t *testing.T) {
+	costs := DeliveryCosts{BrokerageRate: 0.0003, BrokerageMax: 20, GSTRate: 0.18}
+	assert.InDelta(t, 20, costs.Charges(Buy, 100, 1000).Brokerage, 1e-9)
+	assert.InDelta(t, 3.6, costs.Charges(Buy, 100, 1000).GST, 1e-9)
+	assert.InDelta(t, 3, costs.Charges(Sell, 10, 1000).Brokerage, 1e-9)
+}
@@ -0,0 +1,300 @@
+package nse
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores raw NSE response bodies. A zero expires means the entry never
+// expires. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (value []byte, expires time.Time, ok bool)
+	Set(key string, value []byte, expires time.Time)
+}
+
+// GroupSymbols is the cache group of the symbol list derived from the pre-open
+// market data by GetSymbols
+const GroupSymbols EndpointGroup = "symbols"
+
+// NoExpiry is a TTL for entries that never expire
+const NoExpiry time.Duration = -1
+
+// defaultCacheTTLs are used by clients created WithCache. Groups that are not
+// listed are not cached. Historical chunks that end before today are cached
+// with NoExpiry regardless of the GroupHistorical TTL.
+var defaultCacheTTLs = map[EndpointGroup]time.Duration{
+	GroupQuote:      5 * time.Second,
+	GroupMarketData: time.Minute,
+	GroupChart:      time.Minute,
+	GroupHistorical: 5 * time.Minute,
+	GroupSymbols:    24 * time.Hour,
+}
+
+// WithCache caches successful responses in cache, for the TTLs configured
+// with WithCacheTTL. Responses are not cached unless this option is given.
+func WithCache(cache Cache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long responses of an endpoint group are cached. A
+// zero ttl disables caching for the group and NoExpiry keeps entries forever.
+func WithCacheTTL(group EndpointGroup, ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTLs[group] = ttl
+	}
+}
+
+type cacheKey int
+
+const (
+	noCacheKey cacheKey = iota
+	immutableKey
+)
+
+// NoCache returns a context that makes requests skip the cache lookup. The
+// fresh responses are still stored in the cache.
+func NoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey, true)
+}
+
+// immutable returns a context for requests whose responses never change,
+// such as closed historical chunks. They are cached with NoExpiry whatever
+// the group TTL and Cache-Control say.
+func immutable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, immutableKey, true)
+}
+
+func isImmutable(ctx context.Context) bool {
+	v, _ := ctx.Value(immutableKey).(bool)
+	return v
+}
+
+// cacheGet looks key up in the client's cache
+func (c *Client) cacheGet(ctx context.Context, group EndpointGroup, key string) ([]byte, bool) {
+	if c.cache == nil || c.cacheTTL(ctx, group) == 0 {
+		return nil, false
+	}
+	if bypass, _ := ctx.Value(noCacheKey).(bool); bypass {
+		return nil, false
+	}
+	value, expires, ok := c.cache.Get(key)
+	if !ok || (!expires.IsZero() && !time.Now().Before(expires)) {
+		return nil, false
+	}
+	return value, true
+}
+
+// cacheSet stores value in the client's cache. header is the response header
+// whose Cache-Control is honoured, if any.
+func (c *Client) cacheSet(ctx context.Context, group EndpointGroup, key string, value []byte, header http.Header) {
+	ttl := c.cacheTTL(ctx, group)
+	if c.cache == nil || ttl == 0 {
+		return
+	}
+	if !isImmutable(ctx) && header != nil {
+		maxAge, noStore := cacheControl(header.Get("Cache-Control"))
+		if noStore {
+			return
+		}
+		if maxAge >= 0 && (ttl == NoExpiry || maxAge < ttl) {
+			ttl = maxAge
+		}
+		if ttl == 0 {
+			return
+		}
+	}
+
+	var expires time.Time
+	if ttl != NoExpiry {
+		expires = time.Now().Add(ttl)
+	}
+	c.cache.Set(key, value, expires)
+}
+
+func (c *Client) cacheTTL(ctx context.Context, group EndpointGroup) time.Duration {
+	if isImmutable(ctx) {
+		return NoExpiry
+	}
+	return c.cacheTTLs[group]
+}
+
+// cacheControl parses the max-age (-1 when absent) and no-store directives
+// of a Cache-Control header
+func cacheControl(header string) (maxAge time.Duration, noStore bool) {
+	maxAge = -1
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache":
+			noStore = true
+		case strings.HasPrefix(directive, "max-age="):
+			if secs, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && secs >= 0 {
+				maxAge = time.Duration(secs) * time.Second
+			}
+		}
+	}
+	return maxAge, noStore
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entries once it holds more than its capacity
+type MemoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding up to capacity entries
+func NewMemoryCache(capacity int) *MemoryCache {
+	return &MemoryCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Get implements Cache
+func (m *MemoryCache) Get(key string) ([]byte, time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && !time.Now().Before(entry.expires) {
+		m.lru.Remove(el)
+		delete(m.entries, key)
+		return nil, time.Time{}, false
+	}
+	m.lru.MoveToFront(el)
+	return entry.value, entry.expires, true
+}
+
+// Set implements Cache
+func (m *MemoryCache) Set(key string, value []byte, expires time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.entries[key]; ok {
+		el.Value = &memoryEntry{key: key, value: value, expires: expires}
+		m.lru.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.lru.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.capacity > 0 && m.lru.Len() > m.capacity {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
+
+// DiskCache is a Cache storing one file per entry in a directory, so that
+// entries survive restarts
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a DiskCache in dir, creating the directory if needed
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements Cache. Each file starts with the expiry as 8 bytes of Unix
+// nanoseconds, zero for entries that never expire.
+func (d *DiskCache) Get(key string) ([]byte, time.Time, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil || len(data) < 8 {
+		return nil, time.Time{}, false
+	}
+
+	var expires time.Time
+	if nanos := int64(binary.BigEndian.Uint64(data)); nanos != 0 {
+		expires = time.Unix(0, nanos)
+		if !time.Now().Before(expires) {
+			os.Remove(d.path(key))
+			return nil, time.Time{}, false
+		}
+	}
+	return data[8:], expires, true
+}
+
+// Set implements Cache. Write errors are ignored, the entry is simply not cached.
+func (d *DiskCache) Set(key string, value []byte, expires time.Time) {
+	data := make([]byte, 8+len(value))
+	if !expires.IsZero() {
+		binary.BigEndian.PutUint64(data, uint64(expires.UnixNano()))
+	}
+	copy(data[8:], value)
+
+	// write to a temporary file first so readers never see partial entries
+	tmp, err := os.CreateTemp(d.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), d.path(key)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// TieredCache looks entries up in each of its caches in order, copying hits
+// into the faster tiers before it, and stores entries in all of them
+type TieredCache []Cache
+
+// NewTieredCache creates a TieredCache, fastest tier first, e.g. a
+// MemoryCache in front of a DiskCache
+func NewTieredCache(tiers ...Cache) TieredCache {
+	return TieredCache(tiers)
+}
+
+// Get implements Cache
+func (t TieredCache) Get(key string) ([]byte, time.Time, bool) {
+	for i, tier := range t {
+		if value, expires, ok := tier.Get(key); ok {
+			for _, faster := range t[:i] {
+				faster.Set(key, value, expires)
+			}
+			return value, expires, true
+		}
+	}
+	return nil, time.Time{}, false
+}
+
+// Set implements Cache
+func (t TieredCache) Set(key string, value []byte, expires time.Time) {
+	for _, tier := range t {
+		tier.Set(key, value, expires)
+	}
+}
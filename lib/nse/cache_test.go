@@ -0,0 +1,128 @@
+package nse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", []byte("1"), time.Time{})
+	cache.Set("b", []byte("2"), time.Time{})
+	_, _, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	// b is now the least recently used entry
+	cache.Set("c", []byte("3"), time.Time{})
+	_, _, ok = cache.Get("b")
+	assert.False(t, ok)
+	value, _, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", string(value))
+
+	cache.Set("d", []byte("4"), time.Now().Add(-time.Second))
+	_, _, ok = cache.Get("d")
+	assert.False(t, ok)
+}
+
+func TestDiskCache(t *testing.T) {
+	cache, err := NewDiskCache(t.TempDir())
+	require.NoError(t, err)
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Nanosecond)
+	cache.Set("/api/quote-equity?symbol=MITCON", []byte(`{"a":1}`), expires)
+	cache.Set("forever", []byte(`{"b":2}`), time.Time{})
+	cache.Set("stale", []byte(`{}`), time.Now().Add(-time.Second))
+
+	value, gotExpires, ok := cache.Get("/api/quote-equity?symbol=MITCON")
+	assert.True(t, ok)
+	assert.Equal(t, `{"a":1}`, string(value))
+	assert.True(t, expires.Equal(gotExpires))
+
+	_, gotExpires, ok = cache.Get("forever")
+	assert.True(t, ok)
+	assert.True(t, gotExpires.IsZero())
+
+	_, _, ok = cache.Get("stale")
+	assert.False(t, ok)
+}
+
+func TestTieredCache(t *testing.T) {
+	memory := NewMemoryCache(10)
+	disk, err := NewDiskCache(t.TempDir())
+	require.NoError(t, err)
+	disk.Set("k", []byte("v"), time.Time{})
+
+	value, _, ok := NewTieredCache(memory, disk).Get("k")
+	assert.True(t, ok)
+	assert.Equal(t, "v", string(value))
+
+	// the hit was promoted to memory
+	_, _, ok = memory.Get("k")
+	assert.True(t, ok)
+}
+
+func TestClientCache(t *testing.T) {
+	var quotes, preOpens int32
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/quote-equity":
+			atomic.AddInt32(&quotes, 1)
+			if r.URL.Query().Get("symbol") == "NOSTORE" {
+				w.Header().Set("Cache-Control", "no-store")
+			}
+			w.Write([]byte(`{"info":{"symbol":"` + r.URL.Query().Get("symbol") + `"}}`))
+		case "/api/market-data-pre-open":
+			atomic.AddInt32(&preOpens, 1)
+			w.Write([]byte(`{"data":[{"metadata":{"symbol":"MITCON"}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRateLimit(GroupDefault, RateLimit{}),
+		WithCache(NewMemoryCache(100)), WithCacheTTL(GroupMarketData, 0))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, err := c.QuoteEquity(ctx, "MITCON")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&quotes))
+
+	_, err := c.QuoteEquity(NoCache(ctx), "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&quotes))
+
+	for i := 0; i < 2; i++ {
+		_, err := c.QuoteEquity(ctx, "NOSTORE")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&quotes))
+
+	// the pre-open data is not cached, but the symbols derived from it are
+	for i := 0; i < 2; i++ {
+		symbols, err := c.GetSymbols(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"MITCON"}, symbols)
+	}
+	_, err = c.MarketDataPreOpen(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&preOpens))
+}
+
+func TestCacheControl(t *testing.T) {
+	maxAge, noStore := cacheControl("public, max-age=30")
+	assert.Equal(t, 30*time.Second, maxAge)
+	assert.False(t, noStore)
+
+	maxAge, noStore = cacheControl("no-store")
+	assert.Equal(t, time.Duration(-1), maxAge)
+	assert.True(t, noStore)
+}
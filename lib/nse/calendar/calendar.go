@@ -0,0 +1,292 @@
+// Package calendar knows the NSE trading days and the sessions of a
+// trading day.
+//
+// A Calendar is built from the capital market holidays published by NSE on
+// /api/holiday-master, which the nse package fetches with
+// Client.TradingCalendar. Default returns a calendar from a list embedded in
+// the package, for use offline, covering 2018 to 2026.
+//
+// A calendar only knows the holidays of the years it covers, see Covers. In
+// any other year it falls back to treating every weekday as a trading day,
+// so holidays there are taken for trading days without data. NSE publishes
+// the current year only, so Merge a fetched calendar into Default to know
+// both.
+package calendar
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IST is the Asia/Kolkata time zone the market runs in. India has no
+// daylight saving time, so a fixed offset needs no tzdata.
+var IST = time.FixedZone("Asia/Kolkata", 5*60*60+30*60)
+
+// holidayDateFormat is the format of the tradingDate of /api/holiday-master
+const holidayDateFormat = "02-Jan-2006"
+
+//go:embed holidays.json
+var embeddedHolidays []byte
+
+// Holiday is a weekday the market is closed
+type Holiday struct {
+	// Date is midnight IST of the holiday
+	Date        time.Time
+	Description string
+}
+
+// Calendar answers which days are trading days. It is safe for concurrent use.
+type Calendar struct {
+	holidays map[date]Holiday
+	years    map[int]bool
+}
+
+// date is a calendar day in IST
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.In(IST).Date()
+	return date{y, m, d}
+}
+
+func (d date) time() time.Time {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, IST)
+}
+
+// New creates a Calendar with the given holidays
+func New(holidays []Holiday) *Calendar {
+	c := &Calendar{holidays: make(map[date]Holiday, len(holidays)), years: make(map[int]bool)}
+	for _, h := range holidays {
+		d := dateOf(h.Date)
+		h.Date = d.time()
+		c.holidays[d] = h
+		c.years[d.year] = true
+	}
+	return c
+}
+
+// Covers reports whether c knows the holidays of the year of t, i.e. has at
+// least one holiday in it. In years it does not cover every weekday is a
+// trading day.
+func (c *Calendar) Covers(t time.Time) bool {
+	return c.years[dateOf(t).year]
+}
+
+// Merge returns a calendar with the holidays of c, except in the years
+// other covers, which have the holidays of other
+func (c *Calendar) Merge(other *Calendar) *Calendar {
+	var holidays []Holiday
+	for d, h := range c.holidays {
+		if !other.years[d.year] {
+			holidays = append(holidays, h)
+		}
+	}
+	for _, h := range other.holidays {
+		holidays = append(holidays, h)
+	}
+	return New(holidays)
+}
+
+// Parse creates a Calendar from the JSON of /api/holiday-master?type=trading,
+// using the holidays of the capital market (CM) segment
+func Parse(data []byte) (*Calendar, error) {
+	var master map[string][]struct {
+		TradingDate string `json:"tradingDate"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(data, &master); err != nil {
+		return nil, fmt.Errorf("calendar: decoding holidays: %w", err)
+	}
+	segment, ok := master["CM"]
+	if !ok {
+		return nil, fmt.Errorf("calendar: no capital market holidays")
+	}
+
+	holidays := make([]Holiday, 0, len(segment))
+	for _, h := range segment {
+		day, err := time.ParseInLocation(holidayDateFormat, strings.TrimSpace(h.TradingDate), IST)
+		if err != nil {
+			return nil, fmt.Errorf("calendar: invalid holiday date %q: %w", h.TradingDate, err)
+		}
+		holidays = append(holidays, Holiday{Date: day, Description: strings.TrimSpace(h.Description)})
+	}
+	return New(holidays), nil
+}
+
+var (
+	defaultOnce     sync.Once
+	defaultCalendar *Calendar
+)
+
+// Default returns the calendar of the holidays embedded in the package
+func Default() *Calendar {
+	defaultOnce.Do(func() {
+		c, err := Parse(embeddedHolidays)
+		if err != nil {
+			panic(err)
+		}
+		defaultCalendar = c
+	})
+	return defaultCalendar
+}
+
+// Holidays returns the holidays of the calendar in date order
+func (c *Calendar) Holidays() []Holiday {
+	holidays := make([]Holiday, 0, len(c.holidays))
+	for _, h := range c.holidays {
+		holidays = append(holidays, h)
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+	return holidays
+}
+
+// Holiday returns the holiday on the day of t, if any. Weekends are not holidays.
+func (c *Calendar) Holiday(t time.Time) (Holiday, bool) {
+	h, ok := c.holidays[dateOf(t)]
+	return h, ok
+}
+
+// IsTradingDay reports whether the day of t, in IST, is a trading day
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	switch t.In(IST).Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	_, holiday := c.holidays[dateOf(t)]
+	return !holiday
+}
+
+// NextTradingDay returns midnight IST of the first trading day after the day of t
+func (c *Calendar) NextTradingDay(t time.Time) time.Time {
+	day := dateOf(t).time()
+	for {
+		day = day.AddDate(0, 0, 1)
+		if c.IsTradingDay(day) {
+			return day
+		}
+	}
+}
+
+// PrevTradingDay returns midnight IST of the last trading day before the day of t
+func (c *Calendar) PrevTradingDay(t time.Time) time.Time {
+	day := dateOf(t).time()
+	for {
+		day = day.AddDate(0, 0, -1)
+		if c.IsTradingDay(day) {
+			return day
+		}
+	}
+}
+
+// TradingDaysBetween returns midnight IST of the trading days from the day of
+// from to the day of to, both included
+func (c *Calendar) TradingDaysBetween(from, to time.Time) []time.Time {
+	var days []time.Time
+	last := dateOf(to).time()
+	for day := dateOf(from).time(); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if c.IsTradingDay(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// Session is a phase of the trading day
+type Session int
+
+const (
+	// Closed is outside the sessions below, and all day on non-trading days
+	Closed Session = iota
+	// PreOpen is the order entry period of the pre-open session, 09:00 to 09:08
+	PreOpen
+	// PreOpenMatching is the order matching and buffer period that follows, 09:08 to 09:15
+	PreOpenMatching
+	// Normal is the continuous trading session, 09:15 to 15:30
+	Normal
+	// PostClose is the session trading at the closing price, 15:40 to 16:00
+	PostClose
+)
+
+var sessionNames = [...]string{"closed", "pre-open", "pre-open matching", "normal", "post-close"}
+
+func (s Session) String() string {
+	if s < 0 || int(s) >= len(sessionNames) {
+		return fmt.Sprintf("Session(%d)", int(s))
+	}
+	return sessionNames[s]
+}
+
+// Hours are the session times of a trading day
+type Hours struct {
+	PreOpen        time.Time
+	PreOpenClose   time.Time
+	Open           time.Time
+	Close          time.Time
+	PostCloseOpen  time.Time
+	PostCloseClose time.Time
+}
+
+// Hours returns the session times of the day of t, or false if it is not a
+// trading day. Special sessions, such as Muhurat trading, are not known.
+func (c *Calendar) Hours(t time.Time) (Hours, bool) {
+	if !c.IsTradingDay(t) {
+		return Hours{}, false
+	}
+	day := dateOf(t).time()
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	return Hours{
+		PreOpen:        at(9, 0),
+		PreOpenClose:   at(9, 8),
+		Open:           at(9, 15),
+		Close:          at(15, 30),
+		PostCloseOpen:  at(15, 40),
+		PostCloseClose: at(16, 0),
+	}, true
+}
+
+// Session returns the session the market is in at t
+func (c *Calendar) Session(t time.Time) Session {
+	h, ok := c.Hours(t)
+	switch {
+	case !ok:
+		return Closed
+	case t.Before(h.PreOpen):
+		return Closed
+	case t.Before(h.PreOpenClose):
+		return PreOpen
+	case t.Before(h.Open):
+		return PreOpenMatching
+	case t.Before(h.Close):
+		return Normal
+	case t.Before(h.PostCloseOpen):
+		return Closed
+	case t.Before(h.PostCloseClose):
+		return PostClose
+	}
+	return Closed
+}
+
+// IsOpen reports whether the normal session is running at t
+func (c *Calendar) IsOpen(t time.Time) bool {
+	return c.Session(t) == Normal
+}
+
+// NextOpen returns when the next normal session starts after t
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	if h, ok := c.Hours(t); ok && t.Before(h.Open) {
+		return h.Open
+	}
+	h, _ := c.Hours(c.NextTradingDay(t))
+	return h.Open
+}
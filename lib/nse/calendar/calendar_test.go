@@ -0,0 +1,114 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(m time.Month, d int) time.Time {
+	return time.Date(2024, m, d, 0, 0, 0, 0, IST)
+}
+
+func TestParse(t *testing.T) {
+	cal, err := Parse([]byte(`{
+		"CM":[{"tradingDate":"25-Mar-2024","weekDay":"Monday","description":"Holi","Sr_no":1},
+		      {"tradingDate":"29-Mar-2024","weekDay":"Friday","description":"Good Friday","Sr_no":2}],
+		"FO":[{"tradingDate":"01-Jan-2024","weekDay":"Monday","description":"Not a CM holiday","Sr_no":1}]}`))
+	require.NoError(t, err)
+
+	holidays := cal.Holidays()
+	require.Len(t, holidays, 2)
+	assert.Equal(t, Holiday{Date: day(3, 25), Description: "Holi"}, holidays[0])
+	assert.True(t, cal.IsTradingDay(day(1, 1)))
+
+	_, err = Parse([]byte(`{"FO":[]}`))
+	assert.Error(t, err)
+	_, err = Parse([]byte(`{"CM":[{"tradingDate":"2024-03-25"}]}`))
+	assert.Error(t, err)
+}
+
+func TestTradingDays(t *testing.T) {
+	cal := Default()
+
+	assert.True(t, cal.IsTradingDay(day(3, 15)))
+	assert.False(t, cal.IsTradingDay(day(3, 16)), "saturday")
+	assert.False(t, cal.IsTradingDay(day(3, 25)), "holi")
+	// 20:00 UTC on the 24th is already the 25th in India
+	assert.False(t, cal.IsTradingDay(time.Date(2024, 3, 24, 20, 0, 0, 0, time.UTC)))
+
+	holiday, ok := cal.Holiday(day(3, 29))
+	assert.True(t, ok)
+	assert.Equal(t, "Good Friday", holiday.Description)
+
+	assert.Equal(t, day(3, 26), cal.NextTradingDay(day(3, 22)))
+	assert.Equal(t, day(3, 28), cal.PrevTradingDay(day(4, 1)))
+	assert.Equal(t, []time.Time{day(3, 22), day(3, 26), day(3, 27), day(3, 28), day(4, 1)},
+		cal.TradingDaysBetween(day(3, 22), day(4, 1).Add(10*time.Hour)))
+	assert.Empty(t, cal.TradingDaysBetween(day(3, 29), day(3, 31)))
+}
+
+func TestDefault(t *testing.T) {
+	cal := Default()
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, IST) }
+
+	for year := 2018; year <= 2026; year++ {
+		assert.True(t, cal.Covers(date(year, 6, 1)), year)
+	}
+	assert.False(t, cal.Covers(date(2017, 6, 1)))
+	assert.False(t, cal.IsTradingDay(date(2019, 4, 29)), "elections in Mumbai")
+	assert.False(t, cal.IsTradingDay(date(2026, 3, 3)), "holi")
+	// years not covered only skip weekends
+	assert.True(t, cal.IsTradingDay(date(2017, 8, 15)))
+}
+
+func TestMerge(t *testing.T) {
+	embedded := New([]Holiday{
+		{Date: time.Date(2023, 12, 25, 0, 0, 0, 0, IST), Description: "Christmas"},
+		{Date: day(3, 8), Description: "Mahashivratri"},
+	})
+	live := New([]Holiday{{Date: day(3, 25), Description: "Holi"}})
+
+	cal := embedded.Merge(live)
+	assert.False(t, cal.IsTradingDay(time.Date(2023, 12, 25, 0, 0, 0, 0, IST)))
+	// the years of live replace those of embedded
+	assert.True(t, cal.IsTradingDay(day(3, 8)))
+	assert.False(t, cal.IsTradingDay(day(3, 25)))
+	assert.Len(t, cal.Holidays(), 2)
+	assert.Len(t, embedded.Holidays(), 2)
+}
+
+func TestSession(t *testing.T) {
+	cal := Default()
+	at := func(d, h, m int) time.Time { return time.Date(2024, 3, d, h, m, 0, 0, IST) }
+
+	for tm, want := range map[time.Time]Session{
+		at(15, 8, 59):  Closed,
+		at(15, 9, 0):   PreOpen,
+		at(15, 9, 7):   PreOpen,
+		at(15, 9, 8):   PreOpenMatching,
+		at(15, 9, 15):  Normal,
+		at(15, 15, 29): Normal,
+		at(15, 15, 35): Closed,
+		at(15, 15, 45): PostClose,
+		at(15, 16, 0):  Closed,
+		at(16, 10, 0):  Closed,
+		at(25, 10, 0):  Closed,
+	} {
+		assert.Equal(t, want, cal.Session(tm), tm.String())
+	}
+	assert.True(t, cal.IsOpen(time.Date(2024, 3, 15, 5, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "pre-open", PreOpen.String())
+
+	assert.Equal(t, at(15, 9, 15), cal.NextOpen(at(15, 8, 0)))
+	assert.Equal(t, at(26, 9, 15), cal.NextOpen(at(22, 9, 15)))
+
+	hours, ok := cal.Hours(day(3, 15))
+	require.True(t, ok)
+	assert.Equal(t, at(15, 9, 0), hours.PreOpen)
+	assert.Equal(t, at(15, 15, 30), hours.Close)
+	_, ok = cal.Hours(day(3, 16))
+	assert.False(t, ok)
+}
@@ -0,0 +1,178 @@
+package nse
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"nse/lib/nse/calendar"
+)
+
+// IST is the Asia/Kolkata time zone all NSE timestamps are in
+var IST = calendar.IST
+
+// Candle is one OHLCV bar. Time is the start of the bar in IST; for daily
+// bars it is midnight of the trading day.
+type Candle struct {
+	Time      time.Time
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	PrevClose float64
+	Volume    int64
+	Value     float64
+	Trades    int64
+	VWAP      float64
+}
+
+// Candles is a series of candles in ascending time order
+type Candles []Candle
+
+// Between returns the candles from from to to, both included
+func (cs Candles) Between(from, to time.Time) Candles {
+	start := sort.Search(len(cs), func(i int) bool { return !cs[i].Time.Before(from) })
+	end := sort.Search(len(cs), func(i int) bool { return cs[i].Time.After(to) })
+	if start >= end {
+		return nil
+	}
+	return cs[start:end]
+}
+
+// Last returns the last n candles, or all of them if there are fewer
+func (cs Candles) Last(n int) Candles {
+	if n <= 0 {
+		return nil
+	}
+	if n >= len(cs) {
+		return cs
+	}
+	return cs[len(cs)-n:]
+}
+
+// Closes returns the close prices
+func (cs Candles) Closes() []float64 {
+	closes := make([]float64, len(cs))
+	for i, c := range cs {
+		closes[i] = c.Close
+	}
+	return closes
+}
+
+// sortCandles sorts cs by time
+func sortCandles(cs Candles) Candles {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Time.Before(cs[j].Time) })
+	return cs
+}
+
+// Candle converts a row of equity history. ok is false when it has no valid date.
+func (h EquityHistoricalInfo) Candle() (candle Candle, ok bool) {
+	if h.CHTimestamp.IsZero() {
+		return Candle{}, false
+	}
+	return Candle{
+		Time:      h.CHTimestamp.Time,
+		Open:      float64(h.CHOpeningPrice),
+		High:      float64(h.CHTradeHighPrice),
+		Low:       float64(h.CHTradeLowPrice),
+		Close:     float64(h.CHClosingPrice),
+		PrevClose: float64(h.CHPreviousClsPrice),
+		Volume:    int64(h.CHTotTradedQty),
+		Value:     float64(h.CHTotTradedVal),
+		Trades:    int64(h.CHTotalTrades),
+		VWAP:      float64(h.VWAP),
+	}, true
+}
+
+// HistoryCandles converts rows of equity history, e.g. from EquityHistory,
+// to candles. Rows without a valid date are skipped.
+func HistoryCandles(rows []EquityHistoricalInfo) Candles {
+	candles := make(Candles, 0, len(rows))
+	for _, row := range rows {
+		if candle, ok := row.Candle(); ok {
+			candles = append(candles, candle)
+		}
+	}
+	return sortCandles(candles)
+}
+
+// EquityHistoryCandles converts chunks of equity history, such as the Data of
+// EquityHistoryChunks, to the candles EquityHistory returns: in date order,
+// without the days repeated by overlapping or retried chunks
+func EquityHistoryCandles(chunks []EquityHistoricalData) Candles {
+	return HistoryCandles(mergeHistory(chunks))
+}
+
+// Candles converts one chunk of equity history to candles
+func (d EquityHistoricalData) Candles() Candles {
+	return HistoryCandles(d.Data)
+}
+
+// Candles converts index history to candles, taking the volume and value of
+// each day from the turnover records
+func (d IndexHistoricalData) Candles() Candles {
+	type turnover struct {
+		qty, value Float
+	}
+	// keyed by Unix time, since time.Time map keys also compare the location
+	turnovers := make(map[int64]turnover)
+	for _, t := range d.Data.IndexTurnoverRecords {
+		if !t.HITTimestamp.IsZero() {
+			turnovers[t.HITTimestamp.Unix()] = turnover{t.HITTradedQty, t.HITTurnOver}
+		}
+	}
+
+	candles := make(Candles, 0, len(d.Data.IndexCloseOnlineRecords))
+	for _, r := range d.Data.IndexCloseOnlineRecords {
+		if r.EODTimestamp.IsZero() {
+			continue
+		}
+		t := turnovers[r.EODTimestamp.Unix()]
+		candles = append(candles, Candle{
+			Time:   r.EODTimestamp.Time,
+			Open:   float64(r.EODOpenIndexVal),
+			High:   float64(r.EODHighIndexVal),
+			Low:    float64(r.EODLowIndexVal),
+			Close:  float64(r.EODCloseIndexVal),
+			Volume: int64(t.qty),
+			Value:  float64(t.value),
+		})
+	}
+	return sortCandles(candles)
+}
+
+// IndexHistoryCandles converts chunks of index history, such as the Data of
+// IndexHistoryChunks, to the candles IndexHistory returns: in date order,
+// without the days repeated by overlapping or retried chunks
+func IndexHistoryCandles(chunks []IndexHistoricalData) Candles {
+	var candles Candles
+	for _, chunk := range chunks {
+		candles = append(candles, chunk.Candles()...)
+	}
+	return mergeCandles(candles)
+}
+
+// Candles resamples intraday chart data into 1-minute bars; see Ticks.Bars
+func (d IntradayData) Candles() Candles {
+	return d.GraphData.Bars(time.Minute)
+}
+
+// EquityCandles returns the daily candles of symbol over dateRange; see EquityHistory
+func (c *Client) EquityCandles(ctx context.Context, symbol string, dateRange *DateRange) (Candles, error) {
+	history, err := c.EquityHistory(ctx, symbol, dateRange)
+	return HistoryCandles(history), err
+}
+
+// mergeCandles sorts candles by time and drops all but the first candle of
+// each time, e.g. where chunks overlap
+func mergeCandles(candles Candles) Candles {
+	candles = sortCandles(candles)
+	merged := candles[:0]
+	for i, c := range candles {
+		if i > 0 && c.Time.Equal(merged[len(merged)-1].Time) {
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return merged
+}
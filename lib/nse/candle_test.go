@@ -0,0 +1,90 @@
+package nse
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// march returns the Date of day in March 2024
+func march(day int) Date {
+	return Date{time.Date(2024, 3, day, 0, 0, 0, 0, IST)}
+}
+
+func TestHistoryCandles(t *testing.T) {
+	rows := []EquityHistoricalInfo{
+		{CHTimestamp: march(15), CHOpeningPrice: 99, CHTradeHighPrice: 101, CHTradeLowPrice: 98, CHClosingPrice: 100,
+			CHPreviousClsPrice: 99.5, CHTotTradedQty: 1200, CHTotTradedVal: 120000, CHTotalTrades: 40, VWAP: 100.2},
+		{CHTimestamp: march(14), CHClosingPrice: 99.5},
+		{CHClosingPrice: 98}, // no date
+	}
+	candles := HistoryCandles(rows)
+	require.Len(t, candles, 2)
+	assert.True(t, candles[0].Time.Equal(time.Date(2024, 3, 14, 0, 0, 0, 0, IST)))
+	assert.Equal(t, "+0530", candles[1].Time.Format("-0700"))
+	assert.Equal(t, Candle{
+		Time: time.Date(2024, 3, 15, 0, 0, 0, 0, IST), Open: 99, High: 101, Low: 98, Close: 100,
+		PrevClose: 99.5, Volume: 1200, Value: 120000, Trades: 40, VWAP: 100.2,
+	}, candles[1])
+	assert.Equal(t, []float64{99.5, 100}, candles.Closes())
+}
+
+func TestEquityHistoryCandles(t *testing.T) {
+	// the second chunk overlaps the first, as a retried chunk can
+	chunks := []EquityHistoricalData{
+		{Data: []EquityHistoricalInfo{{CHTimestamp: march(14), CHSeries: "EQ", CHClosingPrice: 99.5}, {CHTimestamp: march(13), CHSeries: "EQ", CHClosingPrice: 98}}},
+		{Data: []EquityHistoricalInfo{{CHTimestamp: march(15), CHSeries: "EQ", CHClosingPrice: 100}, {CHTimestamp: march(14), CHSeries: "EQ", CHClosingPrice: 99.5}}},
+	}
+	candles := EquityHistoryCandles(chunks)
+	assert.Equal(t, []float64{98, 99.5, 100}, candles.Closes())
+	assert.Equal(t, HistoryCandles(mergeHistory(chunks)), candles)
+}
+
+func TestIndexCandles(t *testing.T) {
+	var data IndexHistoricalData
+	require.NoError(t, json.Unmarshal([]byte(`{"data":{
+		"indexCloseOnlineRecords":[
+			{"EOD_TIMESTAMP":"15-Mar-2024","EOD_OPEN_INDEX_VAL":22000,"EOD_HIGH_INDEX_VAL":22100,"EOD_LOW_INDEX_VAL":21900,"EOD_CLOSE_INDEX_VAL":22050},
+			{"EOD_TIMESTAMP":"14-Mar-2024","EOD_CLOSE_INDEX_VAL":21950}],
+		"indexTurnoverRecords":[{"HIT_TIMESTAMP":"15-Mar-2024","HIT_TRADED_QTY":5000,"HIT_TURN_OVER":1.5e9}]}}`), &data))
+
+	candles := data.Candles()
+	require.Len(t, candles, 2)
+	assert.Equal(t, 21950.0, candles[0].Close)
+	assert.Zero(t, candles[0].Volume)
+	assert.Equal(t, int64(5000), candles[1].Volume)
+	assert.Equal(t, 1.5e9, candles[1].Value)
+	assert.Equal(t, 22100.0, candles[1].High)
+}
+
+func TestIndexHistoryCandles(t *testing.T) {
+	chunk := func(records string) IndexHistoricalData {
+		var data IndexHistoricalData
+		require.NoError(t, json.Unmarshal([]byte(`{"data":{"indexCloseOnlineRecords":[`+records+`]}}`), &data))
+		return data
+	}
+	// the second chunk overlaps the first, as a retried chunk can
+	candles := IndexHistoryCandles([]IndexHistoricalData{
+		chunk(`{"EOD_TIMESTAMP":"14-Mar-2024","EOD_CLOSE_INDEX_VAL":21950},{"EOD_TIMESTAMP":"13-Mar-2024","EOD_CLOSE_INDEX_VAL":21997.7}`),
+		chunk(`{"EOD_TIMESTAMP":"15-Mar-2024","EOD_CLOSE_INDEX_VAL":22023.35},{"EOD_TIMESTAMP":"14-Mar-2024","EOD_CLOSE_INDEX_VAL":21950}`),
+	})
+	assert.Equal(t, []float64{21997.7, 21950, 22023.35}, candles.Closes())
+}
+
+func TestCandlesBetweenLast(t *testing.T) {
+	var candles Candles
+	for day := 1; day <= 5; day++ {
+		candles = append(candles, Candle{Time: time.Date(2024, 3, day, 0, 0, 0, 0, IST), Close: float64(day)})
+	}
+
+	between := candles.Between(time.Date(2024, 3, 2, 0, 0, 0, 0, IST), time.Date(2024, 3, 4, 0, 0, 0, 0, IST))
+	assert.Equal(t, []float64{2, 3, 4}, between.Closes())
+	assert.Empty(t, candles.Between(time.Date(2024, 4, 1, 0, 0, 0, 0, IST), time.Date(2024, 4, 2, 0, 0, 0, 0, IST)))
+
+	assert.Equal(t, []float64{4, 5}, candles.Last(2).Closes())
+	assert.Len(t, candles.Last(10), 5)
+	assert.Empty(t, candles.Last(0))
+}
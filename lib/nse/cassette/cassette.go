@@ -0,0 +1,217 @@
+// Package cassette records HTTP interactions with NSE to a JSON file and
+// replays them later, so that code using the nse package can be tested
+// without network access.
+//
+// A Cassette is an http.RoundTripper; plug it into a client with
+// nse.WithTransport.
+package cassette
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Mode selects whether a Cassette talks to the network
+type Mode int
+
+const (
+	// Replay serves responses from the cassette file and never touches the network
+	Replay Mode = iota
+	// Record forwards requests to the real transport and saves the responses
+	Record
+)
+
+// ErrNotRecorded is returned in Replay mode for a request that is not on the cassette
+var ErrNotRecorded = errors.New("cassette: request not recorded")
+
+// Request is the part of a request used to match recorded interactions
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// Response is a recorded response. JSON bodies are stored in JSON so that
+// cassettes stay readable and easy to edit; any other body is kept in Body.
+type Response struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+func newResponse(status int, header http.Header, body []byte) Response {
+	if json.Valid(body) {
+		return Response{Status: status, Header: header, JSON: body}
+	}
+	return Response{Status: status, Header: header, Body: string(body)}
+}
+
+func (r Response) body() []byte {
+	if r.JSON != nil {
+		return r.JSON
+	}
+	return []byte(r.Body)
+}
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is an http.RoundTripper that records or replays interactions
+type Cassette struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	// played counts how many times each request has been replayed, so that
+	// repeated identical requests get their recorded responses in order
+	played map[Request]int
+}
+
+// New creates a cassette backed by the file at path. In Replay mode the file
+// must exist. In Record mode requests are sent with transport, or
+// http.DefaultTransport when it is nil, and Save writes them to path.
+func New(path string, mode Mode, transport http.RoundTripper) (*Cassette, error) {
+	c := &Cassette{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		played:    make(map[Request]int),
+	}
+	if c.transport == nil {
+		c.transport = http.DefaultTransport
+	}
+	if mode == Record {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("cassette: decoding %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Load opens an existing cassette in Replay mode
+func Load(path string) (*Cassette, error) {
+	return New(path, Replay, nil)
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := Request{Method: req.Method, URL: req.URL.RequestURI()}
+	if c.mode == Record {
+		return c.record(key, req)
+	}
+	return c.replay(key, req)
+}
+
+func (c *Cassette) replay(key Request, req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matches []Interaction
+	for _, i := range c.interactions {
+		if i.Request == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, key.Method, key.URL)
+	}
+
+	// once all recorded responses were served, keep serving the last one
+	n := c.played[key]
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	c.played[key]++
+
+	recorded := matches[n].Response
+	body := recorded.body()
+	return &http.Response{
+		StatusCode:    recorded.Status,
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassette) record(key Request, req *http.Request) (*http.Response, error) {
+	response, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep cassettes readable by storing bodies uncompressed
+	header := response.Header.Clone()
+	if header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+		header.Del("Content-Encoding")
+		header.Del("Content-Length")
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request:  key,
+		Response: newResponse(response.StatusCode, header, body),
+	})
+	c.mu.Unlock()
+
+	response.Header = header
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	return response, nil
+}
+
+// Save writes the recorded interactions to the cassette file. It is a no-op
+// in Replay mode.
+func (c *Cassette) Save() error {
+	if c.mode != Record {
+		return nil
+	}
+
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
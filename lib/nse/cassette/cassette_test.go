@@ -0,0 +1,53 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls == 1 {
+			w.Write([]byte(`{"n":1}`))
+			return
+		}
+		w.Write([]byte(`{"n":2}`))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := New(path, Record, nil)
+	require.NoError(t, err)
+	client := &http.Client{Transport: recorder}
+	for i := 0; i < 2; i++ {
+		response, err := client.Get(server.URL + "/api/test?x=1")
+		require.NoError(t, err)
+		response.Body.Close()
+	}
+	require.NoError(t, recorder.Save())
+
+	player, err := Load(path)
+	require.NoError(t, err)
+	client = &http.Client{Transport: player}
+	for _, want := range []string{`{"n":1}`, `{"n":2}`, `{"n":2}`} {
+		response, err := client.Get("http://offline.invalid/api/test?x=1")
+		require.NoError(t, err)
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		assert.JSONEq(t, want, string(body))
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, 2, calls)
+
+	_, err = client.Get("http://offline.invalid/api/other")
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
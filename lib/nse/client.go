@@ -0,0 +1,151 @@
+package nse
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"nse/lib/nse/calendar"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Client is a configurable NSE API client. Create one with NewClient.
+type Client struct {
+	http     *resty.Client
+	session  session
+	limiters limiters
+	retry    RetryPolicy
+	stats    stats
+
+	cache     Cache
+	cacheTTLs map[EndpointGroup]time.Duration
+	recorder  *ResponseRecorder
+	logger    *slog.Logger
+	fetchOpts FetchOptions
+	calendar  *calendar.Calendar
+	live      liveCalendar
+}
+
+// Option configures a Client
+type Option func(*options)
+
+type options struct {
+	baseURL    string
+	headers    map[string]string
+	timeout    time.Duration
+	proxy      string
+	transport  http.RoundTripper
+	httpClient *http.Client
+	rateLimits map[EndpointGroup]RateLimit
+	retry      RetryPolicy
+	cache      Cache
+	cacheTTLs  map[EndpointGroup]time.Duration
+	recorder   *ResponseRecorder
+	logger     *slog.Logger
+	fetch      FetchOptions
+	calendar   *calendar.Calendar
+}
+
+// WithBaseURL points the client at a different NSE host, e.g. a staging mirror
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithHeaders sets additional request headers, overriding the defaults with the same name
+func WithHeaders(headers map[string]string) Option {
+	return func(o *options) {
+		for k, v := range headers {
+			o.headers[k] = v
+		}
+	}
+}
+
+// WithUserAgent overrides the User-Agent header
+func WithUserAgent(userAgent string) Option {
+	return func(o *options) {
+		o.headers["User-Agent"] = userAgent
+	}
+}
+
+// WithTimeout sets the timeout for each HTTP request
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithProxy routes all requests through the given proxy URL
+func WithProxy(proxyURL string) Option {
+	return func(o *options) {
+		o.proxy = proxyURL
+	}
+}
+
+// WithTransport sets the underlying http.RoundTripper
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.transport = transport
+	}
+}
+
+// WithHTTPClient uses the given http.Client for all requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// NewClient creates a Client talking to nseindia.com, customised by opts
+func NewClient(opts ...Option) *Client {
+	o := &options{
+		baseURL:    apiURL,
+		headers:    make(map[string]string, len(baseHeaders)),
+		rateLimits: map[EndpointGroup]RateLimit{GroupDefault: defaultRateLimit},
+		retry:      defaultRetryPolicy,
+		logger:     discardLogger,
+		cacheTTLs:  make(map[EndpointGroup]time.Duration, len(defaultCacheTTLs)),
+	}
+	for group, ttl := range defaultCacheTTLs {
+		o.cacheTTLs[group] = ttl
+	}
+	for k, v := range baseHeaders {
+		o.headers[k] = v
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var rc *resty.Client
+	if o.httpClient != nil {
+		rc = resty.NewWithClient(o.httpClient).SetBaseURL(o.baseURL).SetHeaders(o.headers)
+	} else {
+		rc = initRestyClient(o.baseURL, o.headers)
+	}
+	// cookies are managed by the session, not by resty's cookie jar
+	rc.SetCookieJar(nil)
+	if o.transport != nil {
+		rc.SetTransport(o.transport)
+	}
+	if o.proxy != "" {
+		rc.SetProxy(o.proxy)
+	}
+	if o.timeout > 0 {
+		rc.SetTimeout(o.timeout)
+	}
+
+	return &Client{
+		http:     rc,
+		limiters: newLimiters(o.rateLimits),
+		retry:    o.retry,
+
+		cache:     o.cache,
+		cacheTTLs: o.cacheTTLs,
+		recorder:  o.recorder,
+		logger:    o.logger,
+		fetchOpts: o.fetch,
+		calendar:  o.calendar,
+	}
+}
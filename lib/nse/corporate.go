@@ -0,0 +1,45 @@
+package nse
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// Announcements sort by date with package sort, oldest first
+type Announcements []Announcement
+
+func (a Announcements) Len() int           { return len(a) }
+func (a Announcements) Less(i, j int) bool { return a[i].AnDt.Before(a[j].AnDt.Time) }
+func (a Announcements) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// BoardMeetings sort by meeting date with package sort, oldest first
+type BoardMeetings []BoardMeeting
+
+func (b BoardMeetings) Len() int           { return len(b) }
+func (b BoardMeetings) Less(i, j int) bool { return b[i].BmDate.Before(b[j].BmDate.Time) }
+func (b BoardMeetings) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// CorporateActions sort by ex-date with package sort, oldest first
+type CorporateActions []CorporateAction
+
+func (c CorporateActions) Len() int           { return len(c) }
+func (c CorporateActions) Less(i, j int) bool { return c[i].ExDate.Before(c[j].ExDate.Time) }
+func (c CorporateActions) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// CorporateInfo fetches the announcements, board meetings, corporate actions,
+// annual reports, company directory and registrar of symbol
+func (c *Client) CorporateInfo(ctx context.Context, symbol string) (*EquityCorporateInfo, error) {
+	body, err := c.get(ctx, "/api/quote-equity?symbol="+url.QueryEscape(strings.ToUpper(symbol))+"&section=corp_info")
+	if err != nil {
+		return nil, err
+	}
+
+	var info EquityCorporateInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		c.logger.Warn("decoding corporate info failed", "symbol", symbol, "err", err)
+		return nil, err
+	}
+	return &info, nil
+}
@@ -0,0 +1,45 @@
+package nse
+
+import "context"
+
+// The package-level functions below call the corresponding Client method on
+// a shared client created with NewClient(), using context.Background().
+
+// MarketDataPreOpen fetches market data for pre-open
+func MarketDataPreOpen() (*StockData, error) {
+	return defaultClient.MarketDataPreOpen(context.Background())
+}
+
+// GetSymbols retrieves symbols from market data
+func GetSymbols() []string {
+	symbols, err := defaultClient.GetSymbols(context.Background())
+	if err != nil {
+		defaultClient.logger.Error("getting symbols failed", "err", err)
+	}
+	return symbols
+}
+
+// QuoteEquity fetches equity details for a given symbol
+func QuoteEquity(symbol string) (*EquityDetails, error) {
+	return defaultClient.QuoteEquity(context.Background(), symbol)
+}
+
+// QuoteEquityTradeInfo fetches the trade info section of a symbol's quote
+func QuoteEquityTradeInfo(symbol string) (*EquityTradeInfo, error) {
+	return defaultClient.QuoteEquityTradeInfo(context.Background(), symbol)
+}
+
+// ChartDataByIndexPreopen fetches the pre-open intraday chart of a symbol
+func ChartDataByIndexPreopen(symbol string) (*IntradayData, error) {
+	return defaultClient.ChartDataByIndexPreopen(context.Background(), symbol)
+}
+
+// ChartDataByIndex fetches the intraday chart of a symbol
+func ChartDataByIndex(symbol string) (*IntradayData, error) {
+	return defaultClient.ChartDataByIndex(context.Background(), symbol)
+}
+
+// EquityHytoricalData fetches the historical data of a symbol
+func EquityHytoricalData(symbol string, dateRange *DateRange) ([]EquityHistoricalData, error) {
+	return defaultClient.EquityHytoricalData(context.Background(), symbol, dateRange)
+}
@@ -0,0 +1,94 @@
+package nse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// maxErrorBody is the number of response body bytes kept in an APIError
+const maxErrorBody = 256
+
+var (
+	// ErrSymbolNotFound is returned when NSE has no data for the requested symbol
+	ErrSymbolNotFound = errors.New("nse: symbol not found")
+	// ErrUnauthorized is returned when NSE answers 401 or 403 even with fresh session cookies
+	ErrUnauthorized = errors.New("nse: unauthorized")
+	// ErrSessionExpired is returned when NSE keeps serving its bot-challenge page instead of JSON
+	ErrSessionExpired = errors.New("nse: session expired")
+	// ErrRateLimited is returned when NSE answers 429 Too Many Requests
+	ErrRateLimited = errors.New("nse: rate limited")
+	// ErrUpstreamUnavailable is returned on 5xx responses and network failures
+	ErrUpstreamUnavailable = errors.New("nse: upstream unavailable")
+	// ErrUnexpectedStatus is returned on any other status that is not 2xx
+	ErrUnexpectedStatus = errors.New("nse: unexpected status")
+)
+
+// APIError describes a failed request to an NSE endpoint. It unwraps to one of
+// the sentinel errors above, so failures can be classified with errors.Is.
+type APIError struct {
+	StatusCode int
+	Endpoint   string
+	Body       string
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	msg := "nse: request to " + e.Endpoint + " failed"
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" with status %d", e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// transportError wraps an error returned by the HTTP client. Context
+// cancellation is returned as is so callers can match it directly.
+func transportError(ctx context.Context, endpoint string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return &APIError{Endpoint: endpoint, Err: fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)}
+}
+
+// checkResponse returns an *APIError for any response that is not a
+// successful JSON payload
+func checkResponse(endpoint string, response *resty.Response) error {
+	var err error
+	switch code := response.StatusCode(); {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		err = ErrUnauthorized
+	case code == http.StatusNotFound:
+		err = ErrSymbolNotFound
+	case code == http.StatusTooManyRequests:
+		err = ErrRateLimited
+	case code >= 500:
+		err = ErrUpstreamUnavailable
+	case code < 200 || code > 299:
+		err = ErrUnexpectedStatus
+	case sessionExpired(response):
+		err = ErrSessionExpired
+	default:
+		return nil
+	}
+
+	body := response.Body()
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return &APIError{
+		StatusCode: response.StatusCode(),
+		Endpoint:   endpoint,
+		Body:       string(body),
+		Err:        err,
+	}
+}
@@ -0,0 +1,62 @@
+package nse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusNotFound, `{"msg":"no data"}`, ErrSymbolNotFound},
+		{http.StatusTooManyRequests, `{}`, ErrRateLimited},
+		{http.StatusServiceUnavailable, `{}`, ErrUpstreamUnavailable},
+		{http.StatusForbidden, `{}`, ErrUnauthorized},
+		{http.StatusOK, `<html>challenge</html>`, ErrSessionExpired},
+		{http.StatusBadRequest, `{}`, ErrUnexpectedStatus},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		_, err := NewClient(WithBaseURL(server.URL), WithRetry(RetryPolicy{})).MarketDataPreOpen(context.Background())
+		assert.ErrorIs(t, err, tt.want)
+
+		var apiErr *APIError
+		if assert.True(t, errors.As(err, &apiErr)) {
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "/api/market-data-pre-open?key=ALL", apiErr.Endpoint)
+			assert.Equal(t, tt.body, apiErr.Body)
+		}
+		server.Close()
+	}
+}
+
+func TestUnknownSymbol(t *testing.T) {
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClient(WithBaseURL(server.URL)).QuoteEquity(context.Background(), "NOSUCH")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+func TestNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := NewClient(WithBaseURL(server.URL), WithRetry(RetryPolicy{})).QuoteEquity(context.Background(), "MITCON")
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+}
@@ -0,0 +1,153 @@
+package nse
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// defaultParallelism is the number of chunks fetched at once unless
+// configured otherwise
+const defaultParallelism = 4
+
+// FetchOptions control how ranges split into chunks are downloaded
+type FetchOptions struct {
+	// Parallelism is the number of chunks fetched at once; 0 means 4
+	Parallelism int
+	// Retry controls how often and after which delay a failed chunk is tried
+	// again, on top of the retries of each request. The zero value tries each
+	// chunk once.
+	Retry RetryPolicy
+	// Progress, if set, is called after each chunk succeeds or finally fails.
+	// Calls are never concurrent.
+	Progress func(FetchProgress)
+}
+
+// FetchProgress reports how far a chunked download got
+type FetchProgress struct {
+	// Done is the number of chunks finished, successfully or not
+	Done int
+	// Failed is the number of chunks that failed after all retries
+	Failed int
+	// Total is the number of chunks
+	Total int
+	// Bytes is the size of the response bodies received so far
+	Bytes int64
+}
+
+// FetchResult holds the chunks of a download that succeeded, in range order,
+// and the ranges of those that failed
+type FetchResult[T any] struct {
+	Data   []T
+	Ranges []DateRange
+	Failed []ChunkFailure
+}
+
+// Gaps returns the ranges that failed, to be fetched again later
+func (r *FetchResult[T]) Gaps() []DateRange {
+	gaps := make([]DateRange, len(r.Failed))
+	for i, f := range r.Failed {
+		gaps[i] = f.Range
+	}
+	return gaps
+}
+
+// ChunkFunc fetches the chunk covering r and returns its data and the size
+// of the response body
+type ChunkFunc[T any] func(ctx context.Context, r DateRange) (data T, size int, err error)
+
+// WithFetchOptions sets the options of the chunked downloads made by
+// EquityHistory and EquityHytoricalData
+func WithFetchOptions(opts FetchOptions) Option {
+	return func(o *options) {
+		o.fetch = opts
+	}
+}
+
+// FetchChunks calls fetch for every range with at most opts.Parallelism calls
+// at once. Chunks that keep failing are listed in the result rather than
+// failing the download. If ctx is done, the chunks not fetched yet are
+// reported as failed and ctx.Err() is returned with the partial result.
+func FetchChunks[T any](ctx context.Context, ranges []DateRange, opts FetchOptions, fetch ChunkFunc[T]) (*FetchResult[T], error) {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultParallelism
+	}
+
+	data := make([]T, len(ranges))
+	errs := make([]error, len(ranges))
+
+	var mu sync.Mutex
+	progress := FetchProgress{Total: len(ranges)}
+	report := func(size int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		progress.Done++
+		progress.Bytes += int64(size)
+		if err != nil {
+			progress.Failed++
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism && w < len(ranges); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if errs[i] = ctx.Err(); errs[i] != nil {
+					continue
+				}
+				var size int
+				data[i], size, errs[i] = fetchChunk(ctx, ranges[i], opts.Retry, fetch)
+				report(size, errs[i])
+			}
+		}()
+	}
+
+	next := 0
+feed:
+	for ; next < len(ranges); next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	for i := next; i < len(ranges); i++ {
+		errs[i] = ctx.Err()
+	}
+
+	result := &FetchResult[T]{}
+	for i, err := range errs {
+		if err != nil {
+			result.Failed = append(result.Failed, ChunkFailure{Range: ranges[i], Err: err})
+			continue
+		}
+		result.Data = append(result.Data, data[i])
+		result.Ranges = append(result.Ranges, ranges[i])
+	}
+	return result, ctx.Err()
+}
+
+// fetchChunk fetches one chunk, trying again according to policy. The size
+// is the total over all attempts.
+func fetchChunk[T any](ctx context.Context, r DateRange, policy RetryPolicy, fetch ChunkFunc[T]) (T, int, error) {
+	total := 0
+	for attempt := 0; ; attempt++ {
+		data, size, err := fetch(ctx, r)
+		total += size
+		if err == nil || ctx.Err() != nil || attempt >= policy.MaxRetries || errors.Is(err, ErrSymbolNotFound) {
+			return data, total, err
+		}
+		if err := sleep(ctx, policy.backoff(attempt, nil)); err != nil {
+			return data, total, err
+		}
+	}
+}
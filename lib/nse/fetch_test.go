@@ -0,0 +1,84 @@
+package nse
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nse/lib/nse/calendar"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchChunks(t *testing.T) {
+	ranges := getDateRangeChunks(calendar.Default(), time.Date(2024, 1, 1, 0, 0, 0, 0, IST), time.Date(2024, 1, 12, 0, 0, 0, 0, IST), 1)
+	require.Len(t, ranges, 10)
+
+	var running, maxRunning, calls int32
+	attempts := make(map[int]*int32)
+	for _, r := range ranges {
+		attempts[r.Start.Day()] = new(int32)
+	}
+	var progress []FetchProgress
+	opts := FetchOptions{
+		Parallelism: 3,
+		Retry:       RetryPolicy{MaxRetries: 1},
+		Progress:    func(p FetchProgress) { progress = append(progress, p) },
+	}
+
+	result, err := FetchChunks(context.Background(), ranges, opts, func(ctx context.Context, r DateRange) (int, int, error) {
+		atomic.AddInt32(&calls, 1)
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		day := r.Start.Day()
+		attempt := atomic.AddInt32(attempts[day], 1)
+		switch {
+		case day == 4:
+			return 0, 0, ErrUpstreamUnavailable
+		case day == 8 && attempt == 1:
+			return 0, 0, errors.New("flaky")
+		}
+		return day, 100, nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2, 3, 5, 8, 9, 10, 11, 12}, result.Data)
+	assert.Equal(t, ranges[6], result.Ranges[5])
+	require.Len(t, result.Failed, 1)
+	assert.ErrorIs(t, result.Failed[0].Err, ErrUpstreamUnavailable)
+	assert.Equal(t, []DateRange{ranges[3]}, result.Gaps())
+
+	// day 4 and day 8 were tried twice
+	assert.Equal(t, int32(12), calls)
+	assert.LessOrEqual(t, maxRunning, int32(3))
+
+	require.Len(t, progress, 10)
+	assert.Equal(t, FetchProgress{Done: 10, Failed: 1, Total: 10, Bytes: 900}, progress[9])
+}
+
+func TestFetchChunksCanceled(t *testing.T) {
+	ranges := getDateRangeChunks(calendar.Default(), time.Date(2024, 1, 1, 0, 0, 0, 0, IST), time.Date(2024, 1, 12, 0, 0, 0, 0, IST), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	result, err := FetchChunks(ctx, ranges, FetchOptions{Parallelism: 1}, func(ctx context.Context, r DateRange) (int, int, error) {
+		if r.Start.Day() == 2 {
+			cancel()
+			return 0, 0, ctx.Err()
+		}
+		return r.Start.Day(), 0, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{1}, result.Data)
+	assert.Len(t, result.Gaps(), 9)
+}
@@ -0,0 +1,288 @@
+package nse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"nse/lib/nse/calendar"
+)
+
+const (
+	// nseDateFormat is the dd-mm-yyyy format of the from/to parameters of the historical endpoints
+	nseDateFormat = "02-01-2006"
+	// historicalChunkTradingDays is the number of trading days fetched per
+	// historical request, which keeps each request within the 66 calendar
+	// days NSE serves at once in all but exceptionally holiday-heavy months
+	historicalChunkTradingDays = 46
+)
+
+// ChunkFailure is a historical chunk that could not be fetched
+type ChunkFailure struct {
+	Range DateRange
+	Err   error
+}
+
+// ChunkError is returned by the historical APIs when some chunks of the
+// requested range failed. The data of the other chunks is returned with it.
+type ChunkError struct {
+	Symbol string
+	Failed []ChunkFailure
+}
+
+func (e *ChunkError) Error() string {
+	var ranges []string
+	for _, f := range e.Failed {
+		ranges = append(ranges, f.Range.Start.Format(nseDateFormat)+" to "+f.Range.End.Format(nseDateFormat))
+	}
+	msg := fmt.Sprintf("nse: %d historical chunk(s) of %s failed (%s)", len(e.Failed), e.Symbol, strings.Join(ranges, ", "))
+	if len(e.Failed) > 0 {
+		msg += ": " + e.Failed[0].Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed chunks, so errors.Is and errors.As
+// match any of them
+func (e *ChunkError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// Ranges returns the date ranges that failed, e.g. to fetch them again
+func (e *ChunkError) Ranges() []DateRange {
+	ranges := make([]DateRange, len(e.Failed))
+	for i, f := range e.Failed {
+		ranges[i] = f.Range
+	}
+	return ranges
+}
+
+// getDateRangeChunks splits the days from startDate to endDate, both
+// included, into ranges of at most tradingDays trading days of cal. Each
+// range starts on a trading day and ends on its last trading day, except the
+// last one, which ends on endDate. A range without trading days has no chunks.
+// The days are those of startDate and endDate in IST, and the ranges start and
+// end at midnight IST, whatever the time of day and location of startDate and
+// endDate.
+func getDateRangeChunks(cal *calendar.Calendar, startDate, endDate time.Time, tradingDays int) []DateRange {
+	var dateRanges []DateRange
+	startDate, endDate = istDate(startDate), istDate(endDate)
+	var chunk DateRange
+	n := 0
+	for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		if !cal.IsTradingDay(day) {
+			continue
+		}
+		if n == 0 {
+			chunk.Start = day
+		}
+		chunk.End = day
+		n++
+		if n == tradingDays {
+			dateRanges = append(dateRanges, chunk)
+			n = 0
+		}
+	}
+	if n > 0 {
+		chunk.End = endDate
+		dateRanges = append(dateRanges, chunk)
+	}
+
+	return dateRanges
+}
+
+// istDate returns midnight IST of the day t falls on in IST
+func istDate(t time.Time) time.Time {
+	y, m, d := t.In(IST).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, IST)
+}
+
+// closedRange reports whether dr ends before the current trading day, so its
+// data can no longer change
+func closedRange(dr DateRange) bool {
+	y, m, d := time.Now().In(IST).Date()
+	return dr.End.Before(time.Date(y, m, d, 0, 0, 0, 0, IST))
+}
+
+// EquityHistory returns the daily history of symbol over dateRange as a
+// single series sorted by date, with one entry per trading day. A nil
+// dateRange fetches everything since the listing date.
+//
+// Long ranges are fetched in chunks. If some of them fail, the data of the
+// others is returned together with a *ChunkError listing the failed ranges.
+func (c *Client) EquityHistory(ctx context.Context, symbol string, dateRange *DateRange) ([]EquityHistoricalInfo, error) {
+	chunks, err := c.EquityHytoricalData(ctx, symbol, dateRange)
+	var chunkErr *ChunkError
+	if err != nil && !errors.As(err, &chunkErr) {
+		return nil, err
+	}
+	return mergeHistory(chunks), err
+}
+
+// mergeHistory flattens chunks into one series sorted by date, dropping
+// duplicate days
+func mergeHistory(chunks []EquityHistoricalData) []EquityHistoricalInfo {
+	// keyed by Unix time, since time.Time map keys also compare the location
+	type day struct {
+		date   int64
+		series string
+	}
+	seen := make(map[day]bool)
+
+	var history []EquityHistoricalInfo
+	for _, chunk := range chunks {
+		for _, row := range chunk.Data {
+			key := day{row.CHTimestamp.Unix(), row.CHSeries}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			history = append(history, row)
+		}
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].CHTimestamp.Before(history[j].CHTimestamp.Time)
+	})
+	return history
+}
+
+// EquityHytoricalData fetches the daily history of symbol over dateRange, one
+// EquityHistoricalData per chunk of up to 46 trading days, in date order. A
+// nil dateRange fetches everything since the listing date. If some chunks
+// fail, the others are returned together with a *ChunkError.
+func (c *Client) EquityHytoricalData(ctx context.Context, symbol string, dateRange *DateRange) ([]EquityHistoricalData, error) {
+	var ranges []DateRange
+	if dateRange != nil {
+		ranges = []DateRange{*dateRange}
+	}
+	result, err := c.EquityHistoryChunks(ctx, symbol, ranges, c.fetchOpts)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Failed) > 0 {
+		return result.Data, &ChunkError{Symbol: symbol, Failed: result.Failed}
+	}
+	return result.Data, nil
+}
+
+// EquityHistoryChunks downloads the daily history of symbol over ranges, each
+// split into chunks of up to 46 trading days, as configured by opts. No
+// ranges means everything since the listing date. Failed chunks are listed in
+// the result, so that only the gaps need to be fetched again, e.g. by passing
+// result.Gaps() as ranges. The error is only set if the download could not
+// start or ctx is done.
+func (c *Client) EquityHistoryChunks(ctx context.Context, symbol string, ranges []DateRange, opts FetchOptions) (*FetchResult[EquityHistoricalData], error) {
+	details, err := c.QuoteEquity(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	activeSeries := "EQ"
+	if len(details.Info.ActiveSeries) > 0 {
+		activeSeries = details.Info.ActiveSeries[0]
+	}
+
+	if len(ranges) == 0 {
+		if details.Metadata.ListingDate.IsZero() {
+			return nil, fmt.Errorf("nse: listing date of %s is unknown", symbol)
+		}
+		ranges = []DateRange{{Start: details.Metadata.ListingDate.Time, End: time.Now().In(IST)}}
+	}
+	cal := c.Calendar(ctx)
+	var chunks []DateRange
+	for _, r := range ranges {
+		chunks = append(chunks, getDateRangeChunks(cal, r.Start, r.End, historicalChunkTradingDays)...)
+	}
+
+	return FetchChunks(ctx, chunks, opts, func(ctx context.Context, v DateRange) (EquityHistoricalData, int, error) {
+		return c.hytoricalDataAPI(ctx, symbol, activeSeries, v)
+	})
+}
+
+// hytoricalDataAPI fetches one chunk of daily history and returns the size of the response
+func (c *Client) hytoricalDataAPI(ctx context.Context, symbol string, activeSeries string, v DateRange) (EquityHistoricalData, int, error) {
+	url := "/api/historical/cm/equity?symbol=" + url.QueryEscape(strings.ToUpper(symbol)) +
+		"&series=[%22" + url.QueryEscape(activeSeries) + "%22]&from=" + v.Start.Format(nseDateFormat) +
+		"&to=" + v.End.Format(nseDateFormat)
+	if closedRange(v) {
+		ctx = immutable(ctx)
+	}
+
+	var stockData EquityHistoricalData
+	body, err := c.get(ctx, url)
+	if err != nil {
+		c.logger.Warn("fetching historical data failed", "symbol", symbol, "from", v.Start, "to", v.End, "err", err)
+		return stockData, 0, err
+	}
+
+	err = json.Unmarshal(body, &stockData)
+	if err != nil {
+		c.logger.Warn("decoding historical data failed", "symbol", symbol, "err", err)
+	}
+	return stockData, len(body), err
+}
+
+// IndexHistory returns the daily candles of the index indexName, such as
+// "NIFTY 50" or "NIFTY BANK", over dateRange, with the traded quantity and
+// turnover of each day as Volume and Value.
+//
+// Long ranges are fetched in chunks. If some of them fail, the candles of
+// the others are returned together with a *ChunkError listing the failed ranges.
+func (c *Client) IndexHistory(ctx context.Context, indexName string, dateRange DateRange) (Candles, error) {
+	result, err := c.IndexHistoryChunks(ctx, indexName, []DateRange{dateRange}, c.fetchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	candles := IndexHistoryCandles(result.Data)
+	if len(result.Failed) > 0 {
+		return candles, &ChunkError{Symbol: indexName, Failed: result.Failed}
+	}
+	return candles, nil
+}
+
+// IndexHistoryChunks downloads the daily history of the index indexName over
+// ranges, each split into chunks of up to 46 trading days, as configured by
+// opts. It is to IndexHistory what EquityHistoryChunks is to
+// EquityHytoricalData.
+func (c *Client) IndexHistoryChunks(ctx context.Context, indexName string, ranges []DateRange, opts FetchOptions) (*FetchResult[IndexHistoricalData], error) {
+	cal := c.Calendar(ctx)
+	var chunks []DateRange
+	for _, r := range ranges {
+		chunks = append(chunks, getDateRangeChunks(cal, r.Start, r.End, historicalChunkTradingDays)...)
+	}
+	return FetchChunks(ctx, chunks, opts, func(ctx context.Context, v DateRange) (IndexHistoricalData, int, error) {
+		return c.indexHistoryAPI(ctx, indexName, v)
+	})
+}
+
+// indexHistoryAPI fetches one chunk of daily index history and returns the size of the response
+func (c *Client) indexHistoryAPI(ctx context.Context, indexName string, v DateRange) (IndexHistoricalData, int, error) {
+	url := "/api/historical/indicesHistory?indexType=" + queryEscape(strings.ToUpper(indexName)) +
+		"&from=" + v.Start.Format(nseDateFormat) + "&to=" + v.End.Format(nseDateFormat)
+	if closedRange(v) {
+		ctx = immutable(ctx)
+	}
+
+	var indexData IndexHistoricalData
+	body, err := c.get(ctx, url)
+	if err != nil {
+		c.logger.Warn("fetching index history failed", "index", indexName, "from", v.Start, "to", v.End, "err", err)
+		return indexData, 0, err
+	}
+
+	err = json.Unmarshal(body, &indexData)
+	if err != nil {
+		c.logger.Warn("decoding index history failed", "index", indexName, "err", err)
+	}
+	return indexData, len(body), err
+}
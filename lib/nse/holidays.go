@@ -0,0 +1,72 @@
+package nse
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"nse/lib/nse/calendar"
+)
+
+// WithCalendar sets the trading calendar used to split historical ranges
+// into chunks. By default, or with a nil cal, the holidays of
+// /api/holiday-master are fetched on the first chunked download and merged
+// into calendar.Default(), which is used alone if they cannot be fetched.
+// After a failed fetch, NSE is not asked again for calendarRetryAfter.
+func WithCalendar(cal *calendar.Calendar) Option {
+	return func(o *options) {
+		o.calendar = cal
+	}
+}
+
+// calendarRetryAfter is how long a Client uses the embedded holidays alone
+// after fetching those of NSE failed
+const calendarRetryAfter = 15 * time.Minute
+
+// liveCalendar is the default calendar of a Client, fetched once
+type liveCalendar struct {
+	mu     sync.Mutex
+	cal    *calendar.Calendar
+	failed time.Time
+}
+
+// Calendar returns the trading calendar the client chunks history with: the
+// one set with WithCalendar, or else the embedded holidays updated with those
+// published by NSE. A failed fetch is
+// logged and not tried again for calendarRetryAfter, using the embedded
+// holidays meanwhile.
+func (c *Client) Calendar(ctx context.Context) *calendar.Calendar {
+	if c.calendar != nil {
+		return c.calendar
+	}
+	c.live.mu.Lock()
+	defer c.live.mu.Unlock()
+	if c.live.cal == nil {
+		if !c.live.failed.IsZero() && time.Since(c.live.failed) < calendarRetryAfter {
+			return calendar.Default()
+		}
+		live, err := c.TradingCalendar(ctx)
+		if err != nil {
+			c.logger.Warn("fetching trading holidays failed, using the built-in ones", "err", err)
+			c.live.failed = time.Now()
+			return calendar.Default()
+		}
+		c.live.cal = calendar.Default().Merge(live)
+	}
+	return c.live.cal
+}
+
+// TradingCalendar fetches the trading holidays of the capital market segment
+// from NSE
+func (c *Client) TradingCalendar(ctx context.Context) (*calendar.Calendar, error) {
+	body, err := c.get(ctx, "/api/holiday-master?type=trading")
+	if err != nil {
+		return nil, err
+	}
+	cal, err := calendar.Parse(body)
+	if err != nil {
+		c.logger.Warn("decoding trading holidays failed", "err", err)
+		return nil, err
+	}
+	return cal, nil
+}
@@ -0,0 +1,110 @@
+package indicators
+
+import (
+	"math"
+
+	"nse/lib/nse"
+)
+
+// ATR is Wilder's average true range. The true range of the first candle is
+// its high less its low, as there is no close before it.
+type ATR struct {
+	avg     *EMA
+	prev    float64
+	started bool
+}
+
+// NewATR creates an ATR over period candles; periods below 1 are taken as 1
+func NewATR(period int) *ATR {
+	period = max(period, 1)
+	return &ATR{avg: newSmoothed(period, 1/float64(period))}
+}
+
+// Update adds a candle and returns the ATR. ok is false until period candles
+// have been added.
+func (a *ATR) Update(c nse.Candle) (atr float64, ok bool) {
+	tr := c.High - c.Low
+	if a.started {
+		tr = max(tr, math.Abs(c.High-a.prev), math.Abs(c.Low-a.prev))
+	}
+	a.prev, a.started = c.Close, true
+	return a.avg.Update(tr)
+}
+
+// ATRSeries returns the ATR of candles over period
+func ATRSeries(candles nse.Candles, period int) []float64 {
+	return batchCandles(candles, NewATR(period).Update)
+}
+
+// Supertrend is a trailing stop a multiple of the ATR away from the middle
+// of the high and low, below the price in an up trend and above it in a
+// down trend. It starts in a down trend, as on TradingView.
+type Supertrend struct {
+	atr          *ATR
+	multiplier   float64
+	upper, lower float64
+	prevClose    float64
+	up           bool
+	ready        bool
+}
+
+// SupertrendValue is a value of Supertrend
+type SupertrendValue struct {
+	// Value is the stop: the lower band in an up trend, the upper band otherwise
+	Value float64
+	Up    bool
+}
+
+// NewSupertrend creates a Supertrend over an ATR of period candles, usually
+// 10 and 3
+func NewSupertrend(period int, multiplier float64) *Supertrend {
+	return &Supertrend{atr: NewATR(period), multiplier: multiplier}
+}
+
+// Update adds a candle and returns the Supertrend. ok is false until the ATR
+// is warmed up.
+func (s *Supertrend) Update(c nse.Candle) (v SupertrendValue, ok bool) {
+	atr, ok := s.atr.Update(c)
+	prevClose := s.prevClose
+	s.prevClose = c.Close
+	if !ok {
+		return SupertrendValue{}, false
+	}
+
+	mid := (c.High + c.Low) / 2
+	upper, lower := mid+s.multiplier*atr, mid-s.multiplier*atr
+	if !s.ready {
+		s.upper, s.lower, s.ready = upper, lower, true
+	} else {
+		// the bands only move towards the price, unless it crossed them
+		if upper < s.upper || prevClose > s.upper {
+			s.upper = upper
+		}
+		if lower > s.lower || prevClose < s.lower {
+			s.lower = lower
+		}
+		if s.up {
+			s.up = c.Close >= s.lower
+		} else {
+			s.up = c.Close > s.upper
+		}
+	}
+
+	if s.up {
+		return SupertrendValue{Value: s.lower, Up: true}, true
+	}
+	return SupertrendValue{Value: s.upper}, true
+}
+
+// SupertrendSeries returns the Supertrend of candles and whether each value
+// is in an up trend
+func SupertrendSeries(candles nse.Candles, period int, multiplier float64) (values []float64, up []bool) {
+	s := NewSupertrend(period, multiplier)
+	values, up = nan(len(candles)), make([]bool, len(candles))
+	for i, c := range candles {
+		if v, ok := s.Update(c); ok {
+			values[i], up[i] = v.Value, v.Up
+		}
+	}
+	return values, up
+}
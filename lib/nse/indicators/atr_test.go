@@ -0,0 +1,40 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestATRSeries(t *testing.T) {
+	// TA-Lib takes no true range for the first candle, so its ATR was
+	// computed after a leading candle closing in the middle of the first
+	// one's range, which makes the first true range its high less its low
+	// as here and on StockCharts
+	atr := ATRSeries(loadCandles(t), 14)
+	assertWarmUp(t, atr, 13)
+	assert.InDelta(t, 3.22, atr[13], 1e-6)
+	assert.InDelta(t, 3.387143, atr[14], 1e-6)
+	assert.InDelta(t, 3.351769, atr[30], 1e-6)
+	assert.InDelta(t, 3.946882, atr[51], 1e-6)
+}
+
+func TestSupertrendSeries(t *testing.T) {
+	// ta.supertrend(3, 10) of TradingView, which starts in a down trend
+	values, up := SupertrendSeries(loadCandles(t), 10, 3)
+	assertWarmUp(t, values, 9)
+
+	assert.InDelta(t, 117.18, values[9], 1e-6)
+	assert.InDelta(t, 116.26885, values[11], 1e-6)
+	assert.InDelta(t, 115.596465, values[20], 1e-6)
+	assert.InDelta(t, 113.723366, values[25], 1e-6)
+	assert.InDelta(t, 111.593238, values[30], 1e-6)
+	assert.InDelta(t, 100.437243, values[36], 1e-6)
+	assert.InDelta(t, 100.439211, values[37], 1e-6)
+	assert.InDelta(t, 101.01529, values[40], 1e-6)
+	assert.InDelta(t, 109.91889, values[51], 1e-6)
+	// the trend turns up on the 37th candle and stays up
+	for i := 9; i < len(up); i++ {
+		assert.Equal(t, i >= 36, up[i], "candle %d", i)
+	}
+}
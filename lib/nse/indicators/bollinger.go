@@ -0,0 +1,50 @@
+package indicators
+
+import "math"
+
+// Bollinger are Bollinger Bands: an SMA with bands a number of population
+// standard deviations above and below it
+type Bollinger struct {
+	sma   *SMA
+	width float64
+}
+
+// Band is a value of Bollinger
+type Band struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// NewBollinger creates Bollinger Bands over period closes, width standard
+// deviations wide, usually 20 and 2
+func NewBollinger(period int, width float64) *Bollinger {
+	return &Bollinger{sma: NewSMA(period), width: width}
+}
+
+// Update adds a close and returns the bands. ok is false until period closes
+// have been added.
+func (b *Bollinger) Update(close float64) (band Band, ok bool) {
+	mean, ok := b.sma.Update(close)
+	if !ok {
+		return Band{}, false
+	}
+	var variance float64
+	for _, v := range b.sma.window {
+		variance += (v - mean) * (v - mean)
+	}
+	dev := b.width * math.Sqrt(variance/float64(len(b.sma.window)))
+	return Band{Upper: mean + dev, Middle: mean, Lower: mean - dev}, true
+}
+
+// BollingerSeries returns the upper, middle and lower bands of closes
+func BollingerSeries(closes []float64, period int, width float64) (upper, middle, lower []float64) {
+	b := NewBollinger(period, width)
+	upper, middle, lower = nan(len(closes)), nan(len(closes)), nan(len(closes))
+	for i, c := range closes {
+		if band, ok := b.Update(c); ok {
+			upper[i], middle[i], lower[i] = band.Upper, band.Middle, band.Lower
+		}
+	}
+	return upper, middle, lower
+}
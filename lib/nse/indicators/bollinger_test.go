@@ -0,0 +1,29 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBollingerSeries(t *testing.T) {
+	// BBANDS(20, 2, 2, SMA) of TA-Lib, which like Bollinger uses the
+	// population standard deviation
+	upper, middle, lower := BollingerSeries(loadCandles(t).Closes(), 20, 2)
+	assertWarmUp(t, upper, 19)
+	assertWarmUp(t, middle, 19)
+	assertWarmUp(t, lower, 19)
+
+	for _, want := range []struct {
+		i                    int
+		upper, middle, lower float64
+	}{
+		{19, 111.988943, 106.255, 100.521057},
+		{35, 112.402847, 106.627, 100.851153},
+		{51, 123.262132, 114.172, 105.081868},
+	} {
+		assert.InDelta(t, want.upper, upper[want.i], 1e-6)
+		assert.InDelta(t, want.middle, middle[want.i], 1e-6)
+		assert.InDelta(t, want.lower, lower[want.i], 1e-6)
+	}
+}
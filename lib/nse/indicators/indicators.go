@@ -0,0 +1,47 @@
+// Package indicators computes technical indicators over price series, such
+// as the daily candles of EquityHytoricalData.
+//
+// Every indicator has a streaming form, a type updated with one value or
+// candle at a time, and a batch form, a function over a whole series. The
+// streaming forms report whether they are warmed up; the batch forms return
+// a value for every input, which is NaN until the indicator is warmed up.
+// Moving averages are seeded with the simple average of their first values,
+// and RSI and ATR use Wilder's smoothing, as in the usual references.
+package indicators
+
+import (
+	"math"
+
+	"nse/lib/nse"
+)
+
+// nan returns a series of n NaNs
+func nan(n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = math.NaN()
+	}
+	return s
+}
+
+// batch runs update over values, keeping the results of ready updates
+func batch(values []float64, update func(float64) (float64, bool)) []float64 {
+	out := nan(len(values))
+	for i, v := range values {
+		if r, ok := update(v); ok {
+			out[i] = r
+		}
+	}
+	return out
+}
+
+// batchCandles runs update over candles, keeping the results of ready updates
+func batchCandles(candles nse.Candles, update func(nse.Candle) (float64, bool)) []float64 {
+	out := nan(len(candles))
+	for i, c := range candles {
+		if r, ok := update(c); ok {
+			out[i] = r
+		}
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package indicators
+
+import (
+	"encoding/csv"
+	"math"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nse/lib/nse"
+)
+
+// stockChartsCloses is the 10-day moving average example of StockCharts
+var stockChartsCloses = []float64{
+	22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+	22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+	23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+}
+
+// loadCandles reads testdata/mitcon.csv, daily MITCON candles from January
+// to March 2024.
+//
+// The expected values of the tests over these candles were computed with
+// TA-Lib 0.4, through its Go port github.com/markcheno/go-talib (ATR,
+// BBANDS, EMA, STOCH and OBV), and for Supertrend with the ta.supertrend
+// formula of TradingView's Pine Script reference over the TA-Lib ATR. Where
+// TA-Lib differs in convention the test says how it was adjusted.
+func loadCandles(t *testing.T) nse.Candles {
+	t.Helper()
+	f, err := os.Open("testdata/mitcon.csv")
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+
+	var candles nse.Candles
+	for _, r := range records[1:] {
+		day, err := time.ParseInLocation("2006-01-02", r[0], nse.IST)
+		require.NoError(t, err)
+		c := nse.Candle{Time: day}
+		for i, p := range []*float64{&c.Open, &c.High, &c.Low, &c.Close} {
+			*p, err = strconv.ParseFloat(r[i+1], 64)
+			require.NoError(t, err)
+		}
+		c.Volume, err = strconv.ParseInt(r[5], 10, 64)
+		require.NoError(t, err)
+		candles = append(candles, c)
+	}
+	return candles
+}
+
+// assertWarmUp checks that the first n values are NaN and the rest are not
+func assertWarmUp(t *testing.T, values []float64, n int) {
+	t.Helper()
+	for i, v := range values {
+		assert.Equal(t, i < n, math.IsNaN(v), "value %d", i)
+	}
+}
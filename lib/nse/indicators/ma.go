@@ -0,0 +1,71 @@
+package indicators
+
+// SMA is a simple moving average
+type SMA struct {
+	window []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+// NewSMA creates an SMA over period values; periods below 1 are taken as 1
+func NewSMA(period int) *SMA {
+	return &SMA{window: make([]float64, max(period, 1))}
+}
+
+// Update adds v and returns the average of the last period values. ok is
+// false until period values have been added.
+func (s *SMA) Update(v float64) (avg float64, ok bool) {
+	s.sum += v - s.window[s.next]
+	s.window[s.next] = v
+	s.next = (s.next + 1) % len(s.window)
+	if s.count < len(s.window) {
+		s.count++
+		if s.count < len(s.window) {
+			return 0, false
+		}
+	}
+	return s.sum / float64(len(s.window)), true
+}
+
+// SMASeries returns the SMA of values over period
+func SMASeries(values []float64, period int) []float64 {
+	return batch(values, NewSMA(period).Update)
+}
+
+// EMA is an exponential moving average with a smoothing factor of
+// 2/(period+1), seeded with the SMA of its first period values
+type EMA struct {
+	alpha float64
+	seed  *SMA
+	value float64
+	ready bool
+}
+
+// NewEMA creates an EMA over period values; periods below 1 are taken as 1
+func NewEMA(period int) *EMA {
+	period = max(period, 1)
+	return newSmoothed(period, 2/float64(period+1))
+}
+
+// newSmoothed creates an EMA with smoothing factor alpha, seeded with the SMA
+// of its first period values
+func newSmoothed(period int, alpha float64) *EMA {
+	return &EMA{alpha: alpha, seed: NewSMA(period)}
+}
+
+// Update adds v and returns the average. ok is false until period values
+// have been added.
+func (e *EMA) Update(v float64) (avg float64, ok bool) {
+	if e.ready {
+		e.value += e.alpha * (v - e.value)
+		return e.value, true
+	}
+	e.value, e.ready = e.seed.Update(v)
+	return e.value, e.ready
+}
+
+// EMASeries returns the EMA of values over period
+func EMASeries(values []float64, period int) []float64 {
+	return batch(values, NewEMA(period).Update)
+}
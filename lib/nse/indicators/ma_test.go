@@ -0,0 +1,41 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMASeries(t *testing.T) {
+	sma := SMASeries(stockChartsCloses, 10)
+	assertWarmUp(t, sma, 9)
+	want := []float64{22.22, 22.21, 22.23, 22.26, 22.31, 22.42, 22.61, 22.77, 22.91, 23.08,
+		23.21, 23.38, 23.53, 23.65, 23.71, 23.69, 23.61, 23.51, 23.43, 23.28, 23.13}
+	assert.InDeltaSlice(t, want, sma[9:], 0.01)
+}
+
+func TestEMASeries(t *testing.T) {
+	ema := EMASeries(stockChartsCloses, 10)
+	assertWarmUp(t, ema, 9)
+	want := []float64{22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28,
+		23.34, 23.43, 23.51, 23.54, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92}
+	assert.InDeltaSlice(t, want, ema[9:], 0.01)
+}
+
+func TestSMAStreaming(t *testing.T) {
+	sma := NewSMA(3)
+	for _, v := range []float64{1, 2} {
+		_, ok := sma.Update(v)
+		assert.False(t, ok)
+	}
+	for _, want := range []struct{ v, avg float64 }{{3, 2}, {7, 4}, {2, 4}} {
+		avg, ok := sma.Update(want.v)
+		assert.True(t, ok)
+		assert.InDelta(t, want.avg, avg, 1e-9)
+	}
+
+	// a period of 1 is the value itself
+	avg, ok := NewEMA(0).Update(5)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, avg)
+}
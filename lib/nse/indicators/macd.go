@@ -0,0 +1,51 @@
+package indicators
+
+// MACD is the moving average convergence divergence: the difference of a
+// fast and a slow EMA, and a signal line, an EMA of that difference
+type MACD struct {
+	fast, slow, signal *EMA
+}
+
+// MACDValue is a value of MACD
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// NewMACD creates a MACD with the given EMA periods, usually 12, 26 and 9
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{fast: NewEMA(fast), slow: NewEMA(slow), signal: NewEMA(signal)}
+}
+
+// Update adds a close. macdOK is true once the MACD line is warmed up, after
+// the slower of the fast and slow periods, and ok once the signal line is too.
+func (m *MACD) Update(close float64) (v MACDValue, macdOK, ok bool) {
+	fast, fastOK := m.fast.Update(close)
+	slow, slowOK := m.slow.Update(close)
+	if !fastOK || !slowOK {
+		return MACDValue{}, false, false
+	}
+	v.MACD = fast - slow
+	v.Signal, ok = m.signal.Update(v.MACD)
+	if ok {
+		v.Histogram = v.MACD - v.Signal
+	}
+	return v, true, ok
+}
+
+// MACDSeries returns the MACD line, signal line and histogram of closes
+func MACDSeries(closes []float64, fast, slow, signal int) (macd, signalLine, histogram []float64) {
+	m := NewMACD(fast, slow, signal)
+	macd, signalLine, histogram = nan(len(closes)), nan(len(closes)), nan(len(closes))
+	for i, c := range closes {
+		v, macdOK, ok := m.Update(c)
+		if macdOK {
+			macd[i] = v.MACD
+		}
+		if ok {
+			signalLine[i], histogram[i] = v.Signal, v.Histogram
+		}
+	}
+	return macd, signalLine, histogram
+}
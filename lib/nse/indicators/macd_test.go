@@ -0,0 +1,39 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMACDSeries(t *testing.T) {
+	// the EMA(12) less the EMA(26) of TA-Lib, and the signal its EMA(9) of
+	// that from the first MACD on. TA-Lib's MACD function starts the fast EMA
+	// 14 candles late to line it up with the slow one, so its values differ
+	// until they converge.
+	closes := loadCandles(t).Closes()
+	macd, signal, histogram := MACDSeries(closes, 12, 26, 9)
+	assertWarmUp(t, macd, 25)
+	assertWarmUp(t, signal, 33)
+	assertWarmUp(t, histogram, 33)
+
+	assert.InDelta(t, 0.431362, macd[25], 1e-6)
+	assert.InDelta(t, 0.115792, macd[33], 1e-6)
+	assert.InDelta(t, -0.235278, signal[33], 1e-6)
+	assert.InDelta(t, 0.36047, macd[34], 1e-6)
+	assert.InDelta(t, -0.116129, signal[34], 1e-6)
+	assert.InDelta(t, 3.055584, macd[51], 1e-6)
+	assert.InDelta(t, 2.973679, signal[51], 1e-6)
+	assert.InDelta(t, macd[51]-signal[51], histogram[51], 1e-9)
+
+	m := NewMACD(12, 26, 9)
+	for i, c := range closes {
+		v, macdOK, ok := m.Update(c)
+		assert.Equal(t, !math.IsNaN(macd[i]), macdOK)
+		assert.Equal(t, !math.IsNaN(signal[i]), ok)
+		if ok {
+			assert.Equal(t, histogram[i], v.Histogram)
+		}
+	}
+}
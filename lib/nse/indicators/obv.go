@@ -0,0 +1,36 @@
+package indicators
+
+import "nse/lib/nse"
+
+// OBV is the on-balance volume: the running total of volume, added on days
+// the close rises and subtracted on days it falls. It starts at 0 on the
+// first candle.
+type OBV struct {
+	total   float64
+	prev    float64
+	started bool
+}
+
+// NewOBV creates an OBV
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+// Update adds a candle and returns the OBV; ok is always true
+func (o *OBV) Update(c nse.Candle) (obv float64, ok bool) {
+	if o.started {
+		switch {
+		case c.Close > o.prev:
+			o.total += float64(c.Volume)
+		case c.Close < o.prev:
+			o.total -= float64(c.Volume)
+		}
+	}
+	o.prev, o.started = c.Close, true
+	return o.total, true
+}
+
+// OBVSeries returns the OBV of candles
+func OBVSeries(candles nse.Candles) []float64 {
+	return batchCandles(candles, NewOBV().Update)
+}
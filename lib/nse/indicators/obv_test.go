@@ -0,0 +1,21 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOBVSeries(t *testing.T) {
+	// OBV of TA-Lib, which starts from the volume of the first candle
+	// rather than 0, so its values are 90239 higher
+	candles := loadCandles(t)
+	obv := OBVSeries(candles)
+	assertWarmUp(t, obv, 0)
+	require.Equal(t, int64(90239), candles[0].Volume)
+	assert.Equal(t, 90239.0-90239, obv[0])
+	assert.Equal(t, 13401.0-90239, obv[1])
+	assert.Equal(t, 270126.0-90239, obv[10])
+	assert.Equal(t, 939884.0-90239, obv[51])
+}
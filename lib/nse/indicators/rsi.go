@@ -0,0 +1,45 @@
+package indicators
+
+// RSI is Wilder's relative strength index
+type RSI struct {
+	gain, loss *EMA
+	prev       float64
+	started    bool
+}
+
+// NewRSI creates an RSI over period changes; periods below 1 are taken as 1
+func NewRSI(period int) *RSI {
+	period = max(period, 1)
+	return &RSI{
+		gain: newSmoothed(period, 1/float64(period)),
+		loss: newSmoothed(period, 1/float64(period)),
+	}
+}
+
+// Update adds a close and returns the RSI, from 0 to 100. It is 50 when
+// prices have not moved over the period. ok is false until period changes,
+// so period+1 closes, have been added.
+func (r *RSI) Update(close float64) (rsi float64, ok bool) {
+	if !r.started {
+		r.prev, r.started = close, true
+		return 0, false
+	}
+	change := close - r.prev
+	r.prev = close
+	gain, _ := r.gain.Update(max(change, 0))
+	loss, ok := r.loss.Update(max(-change, 0))
+	switch {
+	case !ok:
+		return 0, false
+	case loss == 0 && gain == 0:
+		return 50, true
+	case loss == 0:
+		return 100, true
+	}
+	return 100 - 100/(1+gain/loss), true
+}
+
+// RSISeries returns the RSI of closes over period
+func RSISeries(closes []float64, period int) []float64 {
+	return batch(closes, NewRSI(period).Update)
+}
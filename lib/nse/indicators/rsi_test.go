@@ -0,0 +1,28 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSISeries(t *testing.T) {
+	// the RSI example of StockCharts; its spreadsheet rounds the averages, so
+	// these are the exact values, which differ from it by up to 0.07
+	closes := []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89,
+		46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64, 46.21, 46.25,
+		45.71, 46.45, 45.78, 45.35, 44.03, 44.18, 44.22, 44.57, 43.42, 42.66, 43.13,
+	}
+	rsi := RSISeries(closes, 14)
+	assertWarmUp(t, rsi, 14)
+	want := []float64{70.46, 66.25, 66.48, 69.35, 66.29, 57.92, 62.88, 63.21, 56.01, 62.34,
+		54.67, 50.39, 40.02, 41.49, 41.90, 45.50, 37.32, 33.09, 37.79}
+	assert.InDeltaSlice(t, want, rsi[14:], 0.01)
+}
+
+func TestRSIFlat(t *testing.T) {
+	rsi := RSISeries([]float64{10, 10, 10, 11, 12}, 2)
+	assertWarmUp(t, rsi, 2)
+	assert.Equal(t, []float64{50, 100, 100}, rsi[2:])
+}
@@ -0,0 +1,74 @@
+package indicators
+
+import "nse/lib/nse"
+
+// Stochastic is the stochastic oscillator: %K, where the close is within the
+// range of the last candles, smoothed by an SMA, and %D, an SMA of %K
+type Stochastic struct {
+	highs, lows []float64
+	next, count int
+	smooth      *SMA
+	d           *SMA
+}
+
+// StochasticValue is a value of Stochastic, from 0 to 100
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+// NewStochastic creates a stochastic oscillator over the range of period
+// candles, smoothing %K over smooth values and %D over d values, usually 14,
+// 3 and 3. A smooth of 1 gives the fast oscillator.
+func NewStochastic(period, smooth, d int) *Stochastic {
+	period = max(period, 1)
+	return &Stochastic{
+		highs:  make([]float64, period),
+		lows:   make([]float64, period),
+		smooth: NewSMA(smooth),
+		d:      NewSMA(d),
+	}
+}
+
+// Update adds a candle. kOK is true once %K is warmed up, and ok once %D is
+// too. %K is 50 while the range is empty.
+func (s *Stochastic) Update(c nse.Candle) (v StochasticValue, kOK, ok bool) {
+	s.highs[s.next], s.lows[s.next] = c.High, c.Low
+	s.next = (s.next + 1) % len(s.highs)
+	if s.count < len(s.highs) {
+		s.count++
+		if s.count < len(s.highs) {
+			return StochasticValue{}, false, false
+		}
+	}
+
+	high, low := s.highs[0], s.lows[0]
+	for i := range s.highs {
+		high, low = max(high, s.highs[i]), min(low, s.lows[i])
+	}
+	raw := 50.0
+	if high > low {
+		raw = 100 * (c.Close - low) / (high - low)
+	}
+	if v.K, kOK = s.smooth.Update(raw); !kOK {
+		return StochasticValue{}, false, false
+	}
+	v.D, ok = s.d.Update(v.K)
+	return v, true, ok
+}
+
+// StochasticSeries returns %K and %D of candles
+func StochasticSeries(candles nse.Candles, period, smooth, d int) (k, dLine []float64) {
+	s := NewStochastic(period, smooth, d)
+	k, dLine = nan(len(candles)), nan(len(candles))
+	for i, c := range candles {
+		v, kOK, ok := s.Update(c)
+		if kOK {
+			k[i] = v.K
+		}
+		if ok {
+			dLine[i] = v.D
+		}
+	}
+	return k, dLine
+}
@@ -0,0 +1,33 @@
+package indicators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"nse/lib/nse"
+)
+
+func TestStochasticSeries(t *testing.T) {
+	// STOCH(14, 3, SMA, 3, SMA) of TA-Lib, which starts both lines when %D
+	// starts; the first %K is the average of the fast %K of its STOCHF(14, 1)
+	// over candles 13 to 15
+	k, d := StochasticSeries(loadCandles(t), 14, 3, 3)
+	assertWarmUp(t, k, 15)
+	assertWarmUp(t, d, 17)
+	assert.InDelta(t, 80.064105, k[15], 1e-6)
+	assert.InDelta(t, 79.467644, k[17], 1e-6)
+	assert.InDelta(t, 81.747707, d[17], 1e-6)
+	assert.InDelta(t, 67.689605, k[18], 1e-6)
+	assert.InDelta(t, 77.622874, d[18], 1e-6)
+	assert.InDelta(t, 21.635213, k[30], 1e-6)
+	assert.InDelta(t, 18.7853, d[30], 1e-6)
+	assert.InDelta(t, 61.673069, k[51], 1e-6)
+	assert.InDelta(t, 72.289713, d[51], 1e-6)
+}
+
+func TestStochasticEmptyRange(t *testing.T) {
+	k, _ := StochasticSeries(nse.Candles{{High: 5, Low: 5, Close: 5}, {High: 5, Low: 5, Close: 5}}, 2, 1, 1)
+	assertWarmUp(t, k, 1)
+	assert.Equal(t, 50.0, k[1])
+}
@@ -0,0 +1,108 @@
+package nse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IndexSnapshot fetches the live state of the index indexName, e.g.
+// "NIFTY 50": its metadata, advances and declines, the market status and the
+// quote of every constituent; see IndexDetails.Constituents
+func (c *Client) IndexSnapshot(ctx context.Context, indexName string) (*IndexDetails, error) {
+	indexName = strings.ToUpper(strings.TrimSpace(indexName))
+	body, err := c.get(ctx, "/api/equity-stockIndices?index="+queryEscape(indexName))
+	if err != nil {
+		return nil, err
+	}
+
+	var details IndexDetails
+	if err := json.Unmarshal(body, &details); err != nil {
+		c.logger.Warn("decoding index snapshot failed", "index", indexName, "err", err)
+		return nil, err
+	}
+	// NSE answers unknown indices with an empty object
+	if details.Name == "" && len(details.Data) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, indexName)
+	}
+	return &details, nil
+}
+
+// Constituents returns the stocks of the index, leaving out the row of the
+// index itself that NSE lists first
+func (d *IndexDetails) Constituents() []IndexEquityInfo {
+	constituents := make([]IndexEquityInfo, 0, len(d.Data))
+	for _, e := range d.Data {
+		if e.Priority != 0 || strings.EqualFold(e.Symbol, d.Name) {
+			continue
+		}
+		constituents = append(constituents, e)
+	}
+	return constituents
+}
+
+// IndexCategory is a category of indices, such as "BROAD MARKET INDICES" or
+// "SECTORAL INDICES"
+type IndexCategory struct {
+	Name    string
+	Indices []IndexQuote
+}
+
+// AllIndices fetches the live values of all NSE indices: broad market,
+// sectoral, thematic, strategy and fixed income. See IndexQuote.Snapshot and
+// IndexQuote.History for the details of an index listed.
+func (c *Client) AllIndices(ctx context.Context) (*AllIndicesData, error) {
+	body, err := c.get(ctx, "/api/allIndices")
+	if err != nil {
+		return nil, err
+	}
+
+	var indices AllIndicesData
+	if err := json.Unmarshal(body, &indices); err != nil {
+		c.logger.Warn("decoding all indices failed", "err", err)
+		return nil, err
+	}
+	return &indices, nil
+}
+
+// Snapshot fetches the live state of the index q quotes; see IndexSnapshot
+func (q IndexQuote) Snapshot(ctx context.Context, c *Client) (*IndexDetails, error) {
+	return c.IndexSnapshot(ctx, q.Index)
+}
+
+// History fetches the daily candles of the index q quotes; see IndexHistory
+func (q IndexQuote) History(ctx context.Context, c *Client, dateRange DateRange) (Candles, error) {
+	return c.IndexHistory(ctx, q.Index, dateRange)
+}
+
+// Categories groups the quotes by category, in the order NSE lists them. An
+// index may be in more than one category, e.g. "INDICES ELIGIBLE IN
+// DERIVATIVES" repeats indices of the other categories.
+func (d *AllIndicesData) Categories() []IndexCategory {
+	var categories []IndexCategory
+	positions := make(map[string]int)
+	for _, q := range d.Data {
+		i, ok := positions[q.Key]
+		if !ok {
+			i = len(categories)
+			positions[q.Key] = i
+			categories = append(categories, IndexCategory{Name: q.Key})
+		}
+		categories[i].Indices = append(categories[i].Indices, q)
+	}
+	return categories
+}
+
+// Category returns the categories whose name contains name, ignoring case,
+// e.g. "sectoral" or "broad market"
+func (d *AllIndicesData) Category(name string) []IndexCategory {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	var matches []IndexCategory
+	for _, c := range d.Categories() {
+		if strings.Contains(strings.ToUpper(c.Name), name) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,156 @@
+package nse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Float is a float64 that also decodes from numeric strings such as
+// "123.45" or "1,234.5", and from the "-", "" and null NSE sends for
+// missing values, which decode to 0. Any other text, such as "—", also
+// decodes to 0.
+type Float float64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *Float) UnmarshalJSON(data []byte) error {
+	s, ok := numericText(data)
+	if !ok {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		v = 0
+	}
+	*f = Float(v)
+	return nil
+}
+
+// Int is an int64 that decodes like Float, unparseable text included.
+// Fractional values are truncated, and NaN, infinities and values out of the
+// range of an int64 are rejected with an error.
+type Int int64
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *Int) UnmarshalJSON(data []byte) error {
+	s, ok := numericText(data)
+	if !ok {
+		*i = 0
+		return nil
+	}
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		*i = Int(v)
+		return nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		*i = 0
+		return nil
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which is out of range itself
+	if math.IsNaN(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+		return fmt.Errorf("nse: integer out of range %s", data)
+	}
+	*i = Int(v)
+	return nil
+}
+
+// numericText returns the number in a JSON number or string with any
+// thousands separators removed. ok is false for null and placeholders.
+func numericText(data []byte) (s string, ok bool) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return "", false
+		}
+		s = strings.ReplaceAll(strings.TrimSpace(str), ",", "")
+	} else {
+		s = string(data)
+	}
+	switch s {
+	case "", "-", "--", "null", "NA", "N/A":
+		return "", false
+	}
+	return s, true
+}
+
+// Date is a time in IST decoded from the date formats NSE uses, such as
+// "15-Mar-2024", "15-MAR-2024 15:30:00" or "2024-03-15". Placeholders such as
+// "-" or "" decode to the zero time, and so does any other text.
+type Date struct {
+	time.Time
+}
+
+// dateLayouts are the formats Date accepts, most specific first
+var dateLayouts = []string{
+	"2-Jan-2006 15:04:05",
+	"2-Jan-2006 15:04",
+	"2-Jan-2006",
+	"Jan 2, 2006 15:04:05",
+	"2 Jan 2006",
+	"2006-01-02",
+	"02-01-2006",
+}
+
+// ParseDate parses s in any of the formats Date accepts
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "", "-", "--", "NA", "N/A":
+		return Date{}, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, IST); err == nil {
+			return Date{t}, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return Date{t.In(IST)}, nil
+	}
+	// dates followed by a remark, such as "15-MAR-2024 EOD"
+	if head, _, found := strings.Cut(s, " "); found {
+		if t, err := time.ParseInLocation("2-Jan-2006", head, IST); err == nil {
+			return Date{t}, nil
+		}
+	}
+	return Date{}, fmt.Errorf("nse: invalid date %q", s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Date) UnmarshalJSON(data []byte) error {
+	*d = Date{}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// null, or not a string at all
+		return nil
+	}
+	if date, err := ParseDate(s); err == nil {
+		*d = date
+	}
+	return nil
+}
+
+// MarshalJSON encodes d the way NSE does, as "15-Mar-2024" or
+// "15-Mar-2024 15:30:00", and the zero Date as "-"
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// String formats d like MarshalJSON, without the quotes
+func (d Date) String() string {
+	if d.IsZero() {
+		return "-"
+	}
+	t := d.In(IST)
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("02-Jan-2006")
+	}
+	return t.Format("02-Jan-2006 15:04:05")
+}
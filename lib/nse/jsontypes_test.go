@@ -0,0 +1,108 @@
+package nse
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloat(t *testing.T) {
+	for input, want := range map[string]Float{
+		`123.45`:      123.45,
+		`"123.45"`:    123.45,
+		`"1,234.5"`:   1234.5,
+		`"-"`:         0,
+		`""`:          0,
+		`null`:        0,
+		`"-12.5"`:     -12.5,
+		`" 98.60 "`:   98.6,
+		`1.7104941e3`: 1710.4941,
+	} {
+		var f Float
+		require.NoError(t, json.Unmarshal([]byte(input), &f), input)
+		assert.Equal(t, want, f, input)
+	}
+
+	for _, input := range []string{`"abc"`, `"—"`, `"N.A."`, `true`} {
+		f := Float(1)
+		require.NoError(t, json.Unmarshal([]byte(input), &f), input)
+		assert.Zero(t, f, input)
+	}
+}
+
+func TestInt(t *testing.T) {
+	for input, want := range map[string]Int{
+		`48213`:      48213,
+		`"48213"`:    48213,
+		`"1,23,456"`: 123456,
+		`1.2e6`:      1200000,
+		`"-"`:        0,
+		`null`:       0,
+	} {
+		var i Int
+		require.NoError(t, json.Unmarshal([]byte(input), &i), input)
+		assert.Equal(t, want, i, input)
+	}
+
+	for _, input := range []string{`"12 shares"`, `"—"`} {
+		i := Int(1)
+		require.NoError(t, json.Unmarshal([]byte(input), &i), input)
+		assert.Zero(t, i, input)
+	}
+	for _, input := range []string{`1e19`, `-1e19`, `"9223372036854775808"`, `"1e400"`, `"NaN"`, `"Inf"`} {
+		var i Int
+		assert.Error(t, json.Unmarshal([]byte(input), &i), input)
+	}
+}
+
+func TestDate(t *testing.T) {
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, IST)
+	for input, want := range map[string]time.Time{
+		`"15-Mar-2024"`:              day,
+		`"15-MAR-2024"`:              day,
+		`"8-Sep-2021"`:               time.Date(2021, 9, 8, 0, 0, 0, 0, IST),
+		`"15-Mar-2024 15:30:00"`:     day.Add(15*time.Hour + 30*time.Minute),
+		`"2024-03-15"`:               day,
+		`"Mar 15, 2024 15:30:00"`:    day.Add(15*time.Hour + 30*time.Minute),
+		`"15-MAR-2024 EOD"`:          day,
+		`"2024-03-14T18:30:00.000Z"`: day,
+	} {
+		var d Date
+		require.NoError(t, json.Unmarshal([]byte(input), &d), input)
+		assert.True(t, want.Equal(d.Time), "%s: got %v", input, d.Time)
+	}
+
+	for _, input := range []string{`"-"`, `""`, `null`, `"sometime"`, `"—"`, `20240315`} {
+		d := Date{day}
+		require.NoError(t, json.Unmarshal([]byte(input), &d), input)
+		assert.True(t, d.IsZero(), input)
+	}
+
+	_, err := ParseDate("sometime")
+	assert.Error(t, err)
+
+	data, err := json.Marshal([]Date{{day}, {day.Add(9*time.Hour + 15*time.Minute)}, {}})
+	require.NoError(t, err)
+	assert.Equal(t, `["15-Mar-2024","15-Mar-2024 09:15:00","-"]`, string(data))
+}
+
+func TestPlaceholderQuote(t *testing.T) {
+	var quote EquityDetails
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"metadata":{"listingDate":"08-Sep-2021","pdSectorPe":"-","pdSymbolPe":"24.51","lastUpdateTime":"15-Mar-2024 16:00:00"},
+		"priceInfo":{"lastPrice":"98.60","lowerCP":"78.90","upperCP":"118.30","pPriceBand":"-","vwap":"",
+			"weekHighLow":{"min":72.1,"minDate":"26-Oct-2023","max":173,"maxDate":"-"}},
+		"preOpenMarket":{"totalTradedVolume":"-","IEP":"-"}}`), &quote))
+
+	assert.Equal(t, Float(98.6), quote.PriceInfo.LastPrice)
+	assert.Equal(t, Float(78.9), quote.PriceInfo.LowerCP)
+	assert.Zero(t, quote.PriceInfo.PPriceBand)
+	assert.Zero(t, quote.Metadata.PdSectorPe)
+	assert.Equal(t, Float(24.51), quote.Metadata.PdSymbolPe)
+	assert.Equal(t, "08-Sep-2021", quote.Metadata.ListingDate.String())
+	assert.True(t, quote.PriceInfo.WeekHighLow.MaxDate.IsZero())
+	assert.Zero(t, quote.PreOpenMarket.TotalTradedVolume)
+}
@@ -0,0 +1,48 @@
+package nse
+
+import (
+	"io"
+	"log/slog"
+	"net/url"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// discardLogger is the default logger of a Client
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// WithLogger sends the client's logs to logger. Every request is logged at
+// debug level; by default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		if logger == nil {
+			logger = discardLogger
+		}
+		o.logger = logger
+	}
+}
+
+// logRequest logs one HTTP attempt with its status and latency, taken from
+// the resty trace info
+func (c *Client) logRequest(endpoint string, attempt int, response *resty.Response, err error) {
+	attrs := []any{"endpoint", endpoint}
+	if u, parseErr := url.Parse(endpoint); parseErr == nil {
+		if symbol := u.Query().Get("symbol"); symbol != "" {
+			attrs = append(attrs, "symbol", symbol)
+		} else if index := u.Query().Get("index"); index != "" {
+			attrs = append(attrs, "index", index)
+		} else if index := u.Query().Get("indexType"); index != "" {
+			attrs = append(attrs, "index", index)
+		}
+	}
+	if attempt > 0 {
+		attrs = append(attrs, "attempt", attempt+1)
+	}
+	if response != nil && response.RawResponse != nil {
+		attrs = append(attrs, "status", response.StatusCode(), "latency", response.Request.TraceInfo().TotalTime)
+	}
+	if err != nil {
+		attrs = append(attrs, "err", err)
+	}
+	c.logger.Debug("nse request", attrs...)
+}
@@ -0,0 +1,46 @@
+package nse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"info":{"symbol":"MITCON"}}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	_, err := NewClient(WithBaseURL(server.URL), WithLogger(logger)).QuoteEquity(context.Background(), "MITCON")
+	require.NoError(t, err)
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record map[string]any
+		require.NoError(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 3)
+
+	assert.Equal(t, "nse request", records[0]["msg"])
+	assert.Equal(t, "/", records[0]["endpoint"])
+	assert.Equal(t, "nse session cookies refreshed", records[1]["msg"])
+
+	request := records[2]
+	assert.Equal(t, "DEBUG", request["level"])
+	assert.Equal(t, "/api/quote-equity?symbol=MITCON", request["endpoint"])
+	assert.Equal(t, "MITCON", request["symbol"])
+	assert.Equal(t, float64(200), request["status"])
+	assert.Contains(t, request, "latency")
+}
@@ -1,14 +1,11 @@
 package nse
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
-	"log"
+	"fmt"
 	"net/url"
-	"os"
-	"slices"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -24,7 +21,7 @@ var (
 		"User-Agent":      "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/118.0",
 	}
 
-	client = initRestyClient(apiURL, baseHeaders)
+	defaultClient = NewClient()
 )
 
 // initializeRestyClient initializes and returns a resty.Client with the provided base URL and headers
@@ -32,226 +29,236 @@ func initRestyClient(baseURL string, headers map[string]string) *resty.Client {
 	return resty.New().SetBaseURL(baseURL).SetHeaders(headers)
 }
 
-// getCookie obtains the required cookies from the NSE website's home page
-func getCookie() string {
-	response, err := client.R().EnableTrace().Get("/")
+// getCookie returns the session cookies, obtaining them from the NSE
+// website's home page when the cached ones are missing or expired
+func (c *Client) getCookie(ctx context.Context) (string, error) {
+	return c.session.get(ctx, c.fetchCookie)
+}
+
+// fetchCookie obtains the required cookies from the NSE website's home page
+func (c *Client) fetchCookie(ctx context.Context) (string, time.Time, error) {
+	response, err := c.send(ctx, "/", "")
 	if err != nil {
-		log.Fatal("Failed to get cookie:", err)
+		return "", time.Time{}, err
+	}
+	if !response.IsSuccess() {
+		return "", time.Time{}, checkResponse("/", response)
 	}
 
-	cookies := response.Cookies()
-	var cook []string
+	cookie, expires := sessionCookie(response.Cookies())
+	if cookie == "" {
+		// NSE withholds the session cookies from clients it takes for bots
+		return "", time.Time{}, &APIError{StatusCode: response.StatusCode(), Endpoint: "/", Err: ErrUnauthorized}
+	}
+	c.logger.Debug("nse session cookies refreshed", "expires", expires)
+	return cookie, expires, nil
+}
 
-	requiredCookies := []string{"nsit", "nseappid", "ak_bmsc", "AKA_A2", "bm_mi", "bm_sv"}
-	for _, cookie := range cookies {
-		keyVal := strings.Split(cookie.String(), ";")[0]
-		key := strings.Split(keyVal, "=")[0]
-		if slices.Contains(requiredCookies, key) {
-			cook = append(cook, keyVal)
-		}
+// get returns the body of endpoint, from the cache when possible. Any
+// response that is not a successful JSON payload is returned as an *APIError.
+func (c *Client) get(ctx context.Context, endpoint string) ([]byte, error) {
+	group := groupOf(endpoint)
+	key := c.http.BaseURL + endpoint
+	if body, ok := c.cacheGet(ctx, group, key); ok {
+		return body, nil
 	}
 
-	return strings.Join(cook, "; ")
+	response, err := c.fetch(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	c.cacheSet(ctx, group, key, response.Body(), response.Header())
+	return response.Body(), nil
 }
 
-// marketDataPreOpen fetches market data for pre-open
-func MarketDataPreOpen() (*StockData, error) {
-	cookie := getCookie()
-	response, err := client.R().EnableTrace().SetHeader("Cookie", cookie).Get("/api/market-data-pre-open?key=ALL")
+// fetch requests endpoint with the session cookies. If NSE rejects the
+// session, the cookies are refreshed and the request is retried once.
+func (c *Client) fetch(ctx context.Context, endpoint string) (*resty.Response, error) {
+	cookie, err := c.getCookie(ctx)
 	if err != nil {
-		log.Fatal("Failed to fetch market data:", err)
+		return nil, err
+	}
+	response, err := c.send(ctx, endpoint, cookie)
+	if err != nil {
+		return nil, err
+	}
+	if !sessionExpired(response) {
+		return response, checkResponse(endpoint, response)
 	}
 
-	if response.StatusCode() == 200 {
-		var stockData StockData
-		err := json.Unmarshal(response.Body(), &stockData)
+	c.logger.Debug("nse session rejected, refreshing cookies", "endpoint", endpoint, "status", response.StatusCode())
+	c.session.invalidate(cookie)
+	if cookie, err = c.getCookie(ctx); err != nil {
+		return nil, err
+	}
+	response, err = c.send(ctx, endpoint, cookie)
+	if err != nil {
+		return nil, err
+	}
+	return response, checkResponse(endpoint, response)
+}
+
+// send performs a single GET, waiting for the endpoint's rate limiter and
+// retrying transient failures according to the client's RetryPolicy
+func (c *Client) send(ctx context.Context, endpoint, cookie string) (*resty.Response, error) {
+	limiter := c.limiters.forEndpoint(endpoint)
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(ctx); err != nil {
+			c.stats.drops.Add(1)
+			return nil, err
+		}
+
+		request := c.http.R().SetContext(ctx).EnableTrace()
+		if cookie != "" {
+			request.SetHeader("Cookie", cookie)
+		}
+		c.stats.requests.Add(1)
+		response, err := request.Get(endpoint)
 		if err != nil {
-			log.Println("Error decoding market data:", err)
+			err = transportError(ctx, endpoint, err)
+		} else if recErr := c.recorder.record(response); recErr != nil {
+			c.logger.Warn("recording response failed", "endpoint", endpoint, "err", recErr)
+		}
+		c.logRequest(endpoint, attempt, response, err)
+		if !retryable(response, err) {
+			return response, err
+		}
+		if attempt >= c.retry.MaxRetries {
+			c.stats.drops.Add(1)
+			return response, err
+		}
+
+		if err := sleep(ctx, c.retry.backoff(attempt, response)); err != nil {
+			c.stats.drops.Add(1)
 			return nil, err
 		}
-		return &stockData, nil
+		c.stats.retries.Add(1)
 	}
-	return nil, errors.New("failed to fetch market data")
 }
 
-// getSymbols retrieves symbols from market data
-func GetSymbols() []string {
-	res, err := MarketDataPreOpen()
+// marketDataPreOpen fetches market data for pre-open
+func (c *Client) MarketDataPreOpen(ctx context.Context) (*StockData, error) {
+	body, err := c.get(ctx, "/api/market-data-pre-open?key=ALL")
+	if err != nil {
+		return nil, err
+	}
+
+	var stockData StockData
+	err = json.Unmarshal(body, &stockData)
 	if err != nil {
-		log.Println("Error getting symbols:", err)
+		c.logger.Warn("decoding market data failed", "err", err)
+		return nil, err
 	}
+	return &stockData, nil
+}
+
+// getSymbols retrieves symbols from market data
+func (c *Client) GetSymbols(ctx context.Context) ([]string, error) {
 	var symbols []string
+	key := c.http.BaseURL + "#symbols"
+	if cached, ok := c.cacheGet(ctx, GroupSymbols, key); ok && json.Unmarshal(cached, &symbols) == nil {
+		return symbols, nil
+	}
+
+	res, err := c.MarketDataPreOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
 	for _, val := range res.Data {
 		symbols = append(symbols, val.Metadata.Symbol)
 	}
-	return symbols
+	if data, err := json.Marshal(symbols); err == nil {
+		c.cacheSet(ctx, GroupSymbols, key, data, nil)
+	}
+	return symbols, nil
 }
 
 // quoteEquity fetches equity details for a given symbol
-func QuoteEquity(symbol string) (*EquityDetails, error) {
-	cookie := getCookie()
-	response, err := client.R().EnableTrace().SetHeader("Cookie", cookie).
-		Get("/api/quote-equity?symbol=" + url.QueryEscape(strings.ToUpper(symbol)))
+func (c *Client) QuoteEquity(ctx context.Context, symbol string) (*EquityDetails, error) {
+	body, err := c.get(ctx, "/api/quote-equity?symbol="+url.QueryEscape(strings.ToUpper(symbol)))
 	if err != nil {
-		log.Fatal("Failed to fetch equity details:", err)
-	}
-	if response.StatusCode() == 200 {
-		var stockData EquityDetails
-		// os.WriteFile("TATATECH.json", response.Body(), 0644)
-		err := json.Unmarshal(response.Body(), &stockData)
-		if err != nil {
-			log.Println("Error decoding equity details:", err)
-			return nil, err
-		}
-		return &stockData, nil
+		return nil, err
 	}
-	return nil, errors.New("failed to fetch equity details")
-}
 
-func QuoteEquityTradeInfo(symbol string) (*EquityTradeInfo, error) {
-	cookie := getCookie()
-	response, err := client.R().EnableTrace().SetHeader("Cookie", cookie).
-		Get("/api/quote-equity?symbol=" + url.QueryEscape(strings.ToUpper(symbol)) + "&section=trade_info")
+	var stockData EquityDetails
+	err = json.Unmarshal(body, &stockData)
 	if err != nil {
-		log.Fatal("Failed to fetch equity details:", err)
+		c.logger.Warn("decoding equity details failed", "symbol", symbol, "err", err)
+		return nil, err
 	}
-	if response.StatusCode() == 200 {
-		var stockData EquityTradeInfo
-		os.WriteFile("MITCON.json", response.Body(), 0644)
-		err := json.Unmarshal(response.Body(), &stockData)
-		if err != nil {
-			log.Println("Error decoding equity details:", err)
-			return nil, err
-		}
-		return &stockData, nil
+	// NSE answers unknown symbols with an empty object
+	if stockData.Info.Symbol == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
 	}
-	return nil, errors.New("failed to fetch equity details")
+	return &stockData, nil
 }
 
-func ChartDataByIndexPreopen(symbol string) (*IntradayData, error) {
-	details, _ := QuoteEquity(symbol)
-	identifier := details.Info.Identifier
-	cookie := getCookie()
-	url := "/api/chart-databyindex?index=" + url.QueryEscape(identifier) + "&preopen=true"
-	response, err := client.R().EnableTrace().SetHeader("Cookie", cookie).
-		Get(url)
+func (c *Client) QuoteEquityTradeInfo(ctx context.Context, symbol string) (*EquityTradeInfo, error) {
+	body, err := c.get(ctx, "/api/quote-equity?symbol="+url.QueryEscape(strings.ToUpper(symbol))+"&section=trade_info")
 	if err != nil {
-		log.Fatal("Failed to fetch equity details:", err)
+		return nil, err
 	}
-	if response.StatusCode() == 200 {
-		var stockData IntradayData
-		os.WriteFile("MITCON.json", response.Body(), 0644)
-		err := json.Unmarshal(response.Body(), &stockData)
-		if err != nil {
-			log.Println("Error decoding equity details:", err)
-			return nil, err
-		}
-		return &stockData, nil
+
+	var stockData EquityTradeInfo
+	err = json.Unmarshal(body, &stockData)
+	if err != nil {
+		c.logger.Warn("decoding equity details failed", "symbol", symbol, "err", err)
+		return nil, err
 	}
-	return nil, errors.New("failed to fetch equity details")
+	return &stockData, nil
 }
 
-func ChartDataByIndex(symbol string) (*IntradayData, error) {
-	details, _ := QuoteEquity(symbol)
-	identifier := details.Info.Identifier
-	cookie := getCookie()
-	url := "/api/chart-databyindex?index=" + url.QueryEscape(identifier)
-	response, err := client.R().EnableTrace().SetHeader("Cookie", cookie).
-		Get(url)
+// ChartDataByIndexPreopen fetches the pre-open intraday chart of symbol,
+// looking its chart identifier up with QuoteEquity
+func (c *Client) ChartDataByIndexPreopen(ctx context.Context, symbol string) (*IntradayData, error) {
+	details, err := c.QuoteEquity(ctx, symbol)
 	if err != nil {
-		log.Fatal("Failed to fetch equity details:", err)
+		return nil, err
 	}
-	if response.StatusCode() == 200 {
-		var stockData IntradayData
-		os.WriteFile("MITCON.json", response.Body(), 0644)
-		err := json.Unmarshal(response.Body(), &stockData)
-		if err != nil {
-			log.Println("Error decoding equity details:", err)
-			return nil, err
-		}
-		return &stockData, nil
-	}
-	return nil, errors.New("failed to fetch equity details")
+	return c.IntradayChart(ctx, details.Info.Identifier, true)
 }
 
-func getDateRangeChunks(startDate, endDate time.Time, chunkInDays int) []DateRange {
-	var dateRanges []DateRange
-
-	for chunkStart := startDate; chunkStart.Before(endDate); chunkStart = chunkStart.AddDate(0, 0, chunkInDays) {
-		chunkEnd := chunkStart.AddDate(0, 0, chunkInDays-1)
-
-		if chunkEnd.After(endDate) {
-			chunkEnd = endDate
-		}
-
-		dateRanges = append(dateRanges, DateRange{
-			Start: chunkStart,
-			End:   chunkEnd,
-		})
+// ChartDataByIndex fetches the intraday chart of symbol, looking its chart
+// identifier up with QuoteEquity
+func (c *Client) ChartDataByIndex(ctx context.Context, symbol string) (*IntradayData, error) {
+	details, err := c.QuoteEquity(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
-
-	return dateRanges
+	return c.IntradayChart(ctx, details.Info.Identifier, false)
 }
 
-func EquityHytoricalData(symbol string, dateRange *DateRange) ([]EquityHistoricalData, error) {
-	details, _ := QuoteEquity(symbol)
-	activeSeries := "EQ"
-	if len(details.Info.ActiveSeries) > 0 {
-		activeSeries = details.Info.ActiveSeries[0]
+// IntradayChart fetches the intraday chart of identifier, either the chart
+// identifier of an equity such as "MITCONEQN" (EquityInfo.Identifier) or an
+// index name such as "NIFTY 50", or its pre-open chart when preopen is true
+func (c *Client) IntradayChart(ctx context.Context, identifier string, preopen bool) (*IntradayData, error) {
+	url := "/api/chart-databyindex?index=" + queryEscape(identifier)
+	if strings.Contains(identifier, " ") {
+		// index names are told apart from equity identifiers by their spaces
+		url += "&indices=true"
 	}
-
-	if dateRange == nil {
-		listingDate := details.Metadata.ListingDate
-		start, _ := time.Parse(listingDate, listingDate)
-		end := time.Now()
-		dateRange = &DateRange{Start: start, End: end}
+	if preopen {
+		url += "&preopen=true"
 	}
-	cookie := getCookie()
-	dateRanges := getDateRangeChunks(dateRange.Start, dateRange.End, 66)
-
-	var historicalData []EquityHistoricalData
-	var wg sync.WaitGroup
-	ch := make(chan EquityHistoricalData, len(dateRanges))
-	for _, v := range dateRanges {
-
-		wg.Add(1)
-		go hytoricalDataAPI(symbol, activeSeries, v, cookie, historicalData, &wg, ch)
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return nil, err
 	}
 
-	// Close the channel when all goroutines are done
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
-
-	// Collect results from the channel
-	for stockData := range ch {
-		historicalData = append(historicalData, stockData)
+	var stockData IntradayData
+	err = json.Unmarshal(body, &stockData)
+	if err != nil {
+		c.logger.Warn("decoding chart data failed", "index", identifier, "err", err)
+		return nil, err
 	}
-
-	if historicalData == nil {
-		return nil, errors.New("failed to fetch equity details")
+	if stockData.Identifier == "" {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, identifier)
 	}
-
-	return historicalData, nil
+	return &stockData, nil
 }
 
-func hytoricalDataAPI(symbol string, activeSeries string, v DateRange, cookie string, historicalData []EquityHistoricalData, wg *sync.WaitGroup, ch chan<- EquityHistoricalData) {
-	url := "/api/historical/cm/equity?symbol=" + url.QueryEscape(strings.ToUpper(symbol)) +
-		"&series=[%22" + activeSeries + "%22]&from=" + v.Start.GoString() +
-		"&to=" + v.End.GoString()
-	log.Println(url)
-	response, err := client.R().EnableTrace().SetHeader("Cookie", cookie).
-		Get(url)
-	if err != nil {
-		log.Fatal("Failed to fetch equity details:", err)
-	}
-	if response.StatusCode() == 200 {
-		var stockData EquityHistoricalData
-		err := json.Unmarshal(response.Body(), &stockData)
-		if err != nil {
-			log.Println("Error decoding equity details:", err)
-		}
-		ch <- stockData
-	}
+// queryEscape escapes s for a query parameter, with spaces as %20 rather
+// than + as NSE expects for index names
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
 }
@@ -1,11 +1,46 @@
 package nse
 
 import (
+	"context"
+	"flag"
+	"path/filepath"
+	"sort"
 	"testing"
+	"time"
+
+	"nse/lib/nse/calendar"
+	"nse/lib/nse/cassette"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+var record = flag.Bool("record", false, "record live NSE responses into the testdata cassettes")
+
+// newTestClient returns a client replaying testdata/<test name>.json. With
+// -record the cassette is recorded from nseindia.com instead, unless -short
+// is also given.
+func newTestClient(t *testing.T) *Client {
+	mode := cassette.Replay
+	if *record {
+		if testing.Short() {
+			t.Skip("recording needs nseindia.com, skipped in short mode")
+		}
+		mode = cassette.Record
+	}
+	c, err := cassette.New(filepath.Join("testdata", t.Name()+".json"), mode, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, c.Save())
+	})
+
+	opts := []Option{WithTransport(c)}
+	if !*record {
+		opts = append(opts, WithRateLimit(GroupDefault, RateLimit{}), WithRetry(RetryPolicy{}))
+	}
+	return NewClient(opts...)
+}
+
 func TestInitRestyClient(t *testing.T) {
 	const apiURL = "https://www.nseindia.com"
 	baseHeaders = map[string]string{
@@ -15,27 +50,49 @@ func TestInitRestyClient(t *testing.T) {
 		"User-Agent":      "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/118.0",
 	}
 
-	client = initRestyClient(apiURL, baseHeaders)
+	client := initRestyClient(apiURL, baseHeaders)
 	assert.Equal(t, apiURL, client.HostURL)
 	assert.Equal(t, "en-US,en;q=0.9", client.Header.Get("Accept-Language"))
 }
 
+func TestNewClient(t *testing.T) {
+	c := NewClient(
+		WithBaseURL("https://staging.example.com"),
+		WithUserAgent("go-nse-test"),
+		WithHeaders(map[string]string{"X-Test": "1"}),
+		WithTimeout(5*time.Second),
+	)
+	assert.Equal(t, "https://staging.example.com", c.http.HostURL)
+	assert.Equal(t, "go-nse-test", c.http.Header.Get("User-Agent"))
+	assert.Equal(t, "1", c.http.Header.Get("X-Test"))
+	assert.Equal(t, "en-US,en;q=0.9", c.http.Header.Get("Accept-Language"))
+	assert.Equal(t, 5*time.Second, c.http.GetClient().Timeout)
+
+	// the defaults must not leak between clients
+	assert.Equal(t, baseHeaders["User-Agent"], NewClient().http.Header.Get("User-Agent"))
+}
+
 func TestGetCookie(t *testing.T) {
-	cookie := getCookie()
+	cookie, err := newTestClient(t).getCookie(context.Background())
+	assert.NoError(t, err)
 	assert.NotEmpty(t, cookie)
+	assert.Contains(t, cookie, "nsit=")
+	assert.NotContains(t, cookie, "_abck")
 }
 
 func TestMarketDataPreOpen(t *testing.T) {
-
-	stockData, err := MarketDataPreOpen()
-	assert.NoError(t, err)
-	assert.NotNil(t, stockData)
-	// data, _ := json.Marshal(stockData)
-	// os.WriteFile("marketDataPreOpen.json", data, 0644)
-	assert.NotEmpty(t, stockData.Data)
+	stockData, err := newTestClient(t).MarketDataPreOpen(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, stockData)
+	require.NotEmpty(t, stockData.Data)
+	assert.Equal(t, "TATATECH", stockData.Data[0].Metadata.Symbol)
 }
 
 func TestGetSymbols(t *testing.T) {
+	saved := defaultClient
+	defaultClient = newTestClient(t)
+	defer func() { defaultClient = saved }()
+
 	symbols := GetSymbols()
 	assert.NotEmpty(t, symbols)
 	assert.Contains(t, symbols, "ZEEMEDIA")
@@ -43,10 +100,242 @@ func TestGetSymbols(t *testing.T) {
 }
 
 func TestQuoteEquity(t *testing.T) {
-	var stockData *EquityDetails
-	stockData, err := QuoteEquity("MITCON")
-	assert.NoError(t, err)
-	assert.NotNil(t, stockData)
-	// log.Printf("Response: %+v\n", stockData)
+	c := newTestClient(t)
+	stockData, err := c.QuoteEquity(context.Background(), "mitcon")
+	require.NoError(t, err)
+	require.NotNil(t, stockData)
 	assert.Equal(t, "MITCON", stockData.Info.Symbol)
+	assert.Equal(t, "MITCONEQN", stockData.Info.Identifier)
+	assert.Equal(t, Float(98.6), stockData.PriceInfo.LastPrice)
+
+	_, err = c.QuoteEquity(context.Background(), "NOSUCH")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+}
+
+func TestQuoteEquityTradeInfo(t *testing.T) {
+	tradeInfo, err := newTestClient(t).QuoteEquityTradeInfo(context.Background(), "MITCON")
+	require.NoError(t, err)
+	assert.Len(t, tradeInfo.MarketDeptOrderBook.Bid, 5)
+	assert.Equal(t, Int(48213), tradeInfo.MarketDeptOrderBook.TradeInfo.TotalTradedVolume)
+}
+
+func TestCorporateInfo(t *testing.T) {
+	info, err := newTestClient(t).CorporateInfo(context.Background(), "MITCON")
+	require.NoError(t, err)
+	corp := info.Corporate
+
+	require.Len(t, corp.Announcements, 3)
+	sort.Sort(sort.Reverse(corp.Announcements))
+	assert.Equal(t, "Trading Window", corp.Announcements[0].Desc)
+	assert.True(t, corp.Announcements[0].AnDt.Equal(time.Date(2024, 3, 28, 17, 10, 44, 0, IST)))
+	assert.Equal(t, "Copy of Newspaper Publication", corp.Announcements[1].Desc)
+
+	require.Len(t, corp.BoardMeetings, 2)
+	sort.Sort(corp.BoardMeetings)
+	assert.True(t, corp.BoardMeetings[0].BmDate.Equal(time.Date(2023, 11, 8, 0, 0, 0, 0, IST)))
+	assert.True(t, corp.BoardMeetings[1].BmTimestamp.Equal(time.Date(2024, 2, 2, 16, 20, 33, 0, IST)))
+
+	require.Len(t, corp.CorporateActions, 3)
+	sort.Sort(corp.CorporateActions)
+	assert.Equal(t, "Dividend - Re 0.50 Per Share", corp.CorporateActions[0].Subject)
+	assert.True(t, corp.CorporateActions[1].RecDate.IsZero())
+	events := CorporateEvents(corp.CorporateActions)
+	require.Len(t, events, 2)
+	assert.Equal(t, Bonus, events[1].Kind)
+	assert.Equal(t, 1.5, events[1].Ratio)
+
+	require.Len(t, corp.AnnualReport, 2)
+	assert.Equal(t, "2023", corp.AnnualReport[0].ToYr)
+	require.Len(t, corp.CompanyDirectory, 1)
+	assert.Equal(t, "Pune", corp.CompanyDirectory[0].City)
+}
+
+func TestIndexSnapshot(t *testing.T) {
+	snapshot, err := newTestClient(t).IndexSnapshot(context.Background(), "nifty bank")
+	require.NoError(t, err)
+
+	assert.Equal(t, "NIFTY BANK", snapshot.Name)
+	assert.Equal(t, Int(4), snapshot.Advance.Advances)
+	assert.Equal(t, Int(8), snapshot.Advance.Declines)
+	assert.Equal(t, Float(46594.1), snapshot.Metadata.Last)
+	assert.True(t, snapshot.Metadata.TimeVal.Equal(time.Date(2024, 3, 15, 16, 0, 0, 0, IST)))
+	assert.Equal(t, "Closed", snapshot.MarketStatus.MarketStatus)
+	assert.True(t, snapshot.Date365dAgo.Equal(time.Date(2023, 3, 14, 0, 0, 0, 0, IST)))
+
+	require.Len(t, snapshot.Data, 13)
+	constituents := snapshot.Constituents()
+	require.Len(t, constituents, 12)
+	assert.Equal(t, "HDFCBANK", constituents[0].Symbol)
+	assert.Equal(t, "HDFC Bank Limited", constituents[0].Meta.CompanyName)
+	assert.Equal(t, Float(-0.19), constituents[0].PChange)
+	assert.Equal(t, Float(17.66), constituents[0].NearWKH)
+	assert.Equal(t, Float(-9.42), constituents[0].PerChange365d)
+}
+
+func TestAllIndices(t *testing.T) {
+	indices, err := newTestClient(t).AllIndices(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, indices.Timestamp.Equal(time.Date(2024, 3, 15, 15, 30, 0, 0, IST)))
+	assert.Equal(t, Int(117), indices.Advances)
+	assert.True(t, indices.Dates.OneYearAgo.Equal(time.Date(2023, 3, 15, 0, 0, 0, 0, IST)))
+	require.Len(t, indices.Data, 14)
+
+	categories := indices.Categories()
+	var names []string
+	for _, c := range categories {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{
+		"BROAD MARKET INDICES", "SECTORAL INDICES", "THEMATIC INDICES", "STRATEGY INDICES",
+		"FIXED INCOME INDICES", "INDICES ELIGIBLE IN DERIVATIVES",
+	}, names)
+
+	broad := categories[0].Indices
+	require.Len(t, broad, 4)
+	assert.Equal(t, "NIFTY 50", broad[0].Index)
+	assert.Equal(t, Float(22023.35), broad[0].Last)
+	assert.Equal(t, Float(22.33), broad[0].PE)
+	assert.Equal(t, Float(1.26), broad[0].DY)
+	assert.Equal(t, Int(38), broad[0].Declines)
+	assert.True(t, broad[0].Date30dAgo.Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, IST)))
+	// INDIA VIX has no valuation
+	assert.Equal(t, "INDIA VIX", broad[3].Index)
+	assert.Zero(t, broad[3].PE)
+
+	sectoral := indices.Category("sectoral")
+	require.Len(t, sectoral, 1)
+	assert.Equal(t, "NIFTY BANK", sectoral[0].Indices[0].Index)
+	assert.Len(t, indices.Category("INDICES"), 6)
+	assert.Empty(t, indices.Category("currency"))
+}
+
+func TestChartDataByIndex(t *testing.T) {
+	chart, err := newTestClient(t).ChartDataByIndex(context.Background(), "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, "MITCONEQN", chart.Identifier)
+	assert.Equal(t, Float(98.45), chart.ClosePrice)
+	require.Len(t, chart.GraphData, 12)
+	assert.Equal(t, Tick{Time: time.Date(2024, 3, 15, 9, 15, 0, 0, IST), Price: 99.1}, chart.GraphData[0])
+	assert.Equal(t, 98.6, chart.GraphData[11].Price)
+}
+
+func TestIntradayChartIndex(t *testing.T) {
+	chart, err := newTestClient(t).IntradayChart(context.Background(), "NIFTY 50", false)
+	require.NoError(t, err)
+	assert.Equal(t, "NIFTY 50", chart.Identifier)
+	require.Len(t, chart.GraphData, 16)
+
+	bars := chart.GraphData.Bars(15 * time.Minute)
+	require.Len(t, bars, 2)
+	assert.Equal(t, Candle{
+		Time: time.Date(2024, 3, 15, 9, 15, 0, 0, IST), Open: 22064.85, High: 22071.2, Low: 21998.4, Close: 22012.55,
+	}, bars[0])
+	assert.Equal(t, 22020.3, bars[1].Close)
+	assert.Equal(t, 22012.55, bars[1].PrevClose)
+}
+
+func TestChartDataByIndexPreopen(t *testing.T) {
+	chart, err := newTestClient(t).ChartDataByIndexPreopen(context.Background(), "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, "MITCONEQN", chart.Identifier)
+	assert.NotEmpty(t, chart.GraphData)
+}
+
+func TestEquityHytoricalData(t *testing.T) {
+	dateRange := &DateRange{
+		Start: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+	data, err := newTestClient(t).EquityHytoricalData(context.Background(), "MITCON", dateRange)
+	assert.NoError(t, err)
+	if assert.Len(t, data, 1) {
+		assert.Len(t, data[0].Data, 5)
+	}
+}
+
+func TestEquityHistory(t *testing.T) {
+	dateRange := &DateRange{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+	history, err := newTestClient(t).EquityHistory(context.Background(), "MITCON", dateRange)
+	require.NoError(t, err)
+	require.Len(t, history, 52)
+	assert.Equal(t, "2024-01-01", history[0].CHTimestamp.Format("2006-01-02"))
+	assert.Equal(t, "2024-03-15", history[len(history)-1].CHTimestamp.Format("2006-01-02"))
+	for i := 1; i < len(history); i++ {
+		assert.True(t, history[i-1].CHTimestamp.Before(history[i].CHTimestamp.Time))
+	}
+}
+
+func TestEquityHistoryPartialFailure(t *testing.T) {
+	dateRange := &DateRange{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+	history, err := newTestClient(t).EquityHistory(context.Background(), "MITCON", dateRange)
+	assert.Len(t, history, 6)
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+
+	var chunkErr *ChunkError
+	require.ErrorAs(t, err, &chunkErr)
+	assert.Equal(t, []DateRange{{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, IST),
+		End:   time.Date(2024, 3, 6, 0, 0, 0, 0, IST),
+	}}, chunkErr.Ranges())
+}
+
+func TestIndexHistory(t *testing.T) {
+	dateRange := DateRange{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, IST),
+		End:   time.Date(2024, 3, 15, 0, 0, 0, 0, IST),
+	}
+	candles, err := newTestClient(t).IndexHistory(context.Background(), "NIFTY 50", dateRange)
+	require.NoError(t, err)
+	require.Len(t, candles, 53)
+	assert.True(t, candles[0].Time.Equal(dateRange.Start))
+	for i := 1; i < len(candles); i++ {
+		assert.True(t, candles[i-1].Time.Before(candles[i].Time))
+	}
+
+	last := candles.Last(2)
+	assert.Equal(t, Candle{
+		Time: time.Date(2024, 3, 14, 0, 0, 0, 0, IST), Open: 21294.26, High: 22149.63, Low: 21175.67, Close: 21845.49,
+		Volume: 138463 * 4000, Value: 138463 * 4000 * 410.5,
+	}, last[0])
+	// NSE has not published the turnover of the last day yet
+	assert.Equal(t, 21906.5, last[1].Close)
+	assert.Zero(t, last[1].Volume)
+}
+
+func TestGetDateRangeChunks(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, IST) }
+
+	cal := calendar.Default()
+
+	assert.Equal(t, []DateRange{{Start: day(3, 15), End: day(3, 15)}}, getDateRangeChunks(cal, day(3, 15), day(3, 15), 46))
+	assert.Equal(t, []DateRange{
+		{Start: day(1, 1), End: day(3, 6)},
+		{Start: day(3, 7), End: day(3, 15)},
+	}, getDateRangeChunks(cal, day(1, 1), day(3, 15), 46))
+
+	// weekends and holidays are skipped and not counted
+	assert.Empty(t, getDateRangeChunks(cal, day(3, 9), day(3, 10), 46))
+	assert.Equal(t, []DateRange{
+		{Start: day(3, 20), End: day(3, 22)},
+		{Start: day(3, 26), End: day(3, 28)},
+	}, getDateRangeChunks(cal, day(3, 20), day(3, 31), 3))
+	assert.Equal(t, []DateRange{
+		{Start: day(3, 26), End: day(3, 28)},
+		{Start: day(4, 1), End: day(4, 2)},
+	}, getDateRangeChunks(cal, day(3, 23), day(4, 2), 3))
+
+	// only the IST dates count, not the time of day or the location
+	assert.Equal(t, []DateRange{{Start: day(3, 15), End: day(3, 15)}},
+		getDateRangeChunks(cal, day(3, 15).Add(15*time.Hour), day(3, 15).Add(9*time.Hour), 46))
+	tokyo := time.FixedZone("JST", 9*60*60)
+	chunks := getDateRangeChunks(cal, time.Date(2024, 3, 16, 2, 0, 0, 0, tokyo), time.Date(2024, 3, 16, 2, 0, 0, 0, tokyo), 46)
+	assert.Equal(t, []DateRange{{Start: day(3, 15), End: day(3, 15)}}, chunks)
+	assert.Equal(t, "15-03-2024", chunks[0].Start.Format(nseDateFormat))
 }
@@ -0,0 +1,464 @@
+// Package nsetest provides an in-process fake of the NSE website for testing
+// code that uses the nse package.
+//
+// The fake serves the endpoints the library uses: the cookie handshake on
+// "/", /api/quote-equity (including the trade_info and corp_info sections),
+// /api/market-data-pre-open, /api/chart-databyindex (for equities and
+// indices), /api/equity-stockIndices, /api/allIndices,
+// /api/historical/cm/equity, /api/historical/indicesHistory and
+// /api/holiday-master. It starts with a small set of fixtures for the MITCON
+// symbol, the NIFTY 50 and NIFTY BANK indices, the list of all indices and
+// the 2024 holidays, which tests can replace or extend, and it can be told to
+// fail requests in the ways NSE does.
+package nsetest
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nse/lib/nse"
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// sessionCookie is the cookie the fake requires on every /api request
+const sessionCookie = "nsit"
+
+// Failure describes how the server should answer a request instead of
+// serving fixture data. A Failure with only Delay set serves the normal
+// response late.
+type Failure struct {
+	Status int
+	Header http.Header
+	Body   string
+	Delay  time.Duration
+}
+
+// Unauthorized answers 401 as NSE does for a missing or stale session
+func Unauthorized() Failure {
+	return Failure{Status: http.StatusUnauthorized, Body: `{"msg":"Unauthorized"}`}
+}
+
+// RateLimited answers 429 with the given Retry-After
+func RateLimited(retryAfter time.Duration) Failure {
+	header := http.Header{}
+	header.Set("Retry-After", strconv.Itoa(int(retryAfter/time.Second)))
+	return Failure{Status: http.StatusTooManyRequests, Header: header}
+}
+
+// Unavailable answers 503
+func Unavailable() Failure {
+	return Failure{Status: http.StatusServiceUnavailable, Body: "Service Unavailable"}
+}
+
+// MalformedJSON answers 200 with a truncated JSON body
+func MalformedJSON() Failure {
+	return Failure{Status: http.StatusOK, Body: `{"info":{"symbol":"MITC`}
+}
+
+// BotChallenge answers 200 with an HTML page instead of JSON
+func BotChallenge() Failure {
+	header := http.Header{}
+	header.Set("Content-Type", "text/html")
+	return Failure{Status: http.StatusOK, Header: header, Body: "<html><body>Access Denied</body></html>"}
+}
+
+// Slow serves the normal response after d
+func Slow(d time.Duration) Failure {
+	return Failure{Delay: d}
+}
+
+// Server is a fake NSE website
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	generation    int
+	quotes        map[string]json.RawMessage
+	tradeInfo     map[string]json.RawMessage
+	corpInfo      map[string]json.RawMessage
+	indices       map[string]json.RawMessage
+	allIndices    json.RawMessage
+	preOpen       json.RawMessage
+	charts        map[string]json.RawMessage
+	preOpenCharts map[string]json.RawMessage
+	history       map[string][]nse.EquityHistoricalInfo
+	indexHistory  map[string]nse.Candles
+	holidays      json.RawMessage
+	failures      map[string][]Failure
+	requests      map[string]int
+}
+
+// NewServer starts a fake NSE server seeded with the default fixtures. It is
+// closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		quotes:        make(map[string]json.RawMessage),
+		tradeInfo:     make(map[string]json.RawMessage),
+		corpInfo:      make(map[string]json.RawMessage),
+		indices:       make(map[string]json.RawMessage),
+		charts:        make(map[string]json.RawMessage),
+		preOpenCharts: make(map[string]json.RawMessage),
+		history:       make(map[string][]nse.EquityHistoricalInfo),
+		indexHistory:  make(map[string]nse.Candles),
+		failures:      make(map[string][]Failure),
+		requests:      make(map[string]int),
+	}
+	s.seed(t)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) seed(t testing.TB) {
+	t.Helper()
+	load := func(name string, v any) {
+		data, err := fixtures.ReadFile("fixtures/" + name)
+		if err == nil {
+			err = json.Unmarshal(data, v)
+		}
+		if err != nil {
+			t.Fatalf("nsetest: loading fixture %s: %v", name, err)
+		}
+	}
+
+	load("quote-equity.json", &s.quotes)
+	load("trade-info.json", &s.tradeInfo)
+	load("corp-info.json", &s.corpInfo)
+	load("index-snapshot.json", &s.indices)
+	load("all-indices.json", &s.allIndices)
+	load("market-data-pre-open.json", &s.preOpen)
+	load("chart-databyindex.json", &s.charts)
+	load("chart-databyindex-preopen.json", &s.preOpenCharts)
+	load("historical.json", &s.history)
+	load("index-history.json", &s.indexHistory)
+	load("holiday-master.json", &s.holidays)
+}
+
+// Client returns an nse.Client talking to the server. Rate limiting and
+// retries are disabled unless enabled again through opts.
+func (s *Server) Client(opts ...nse.Option) *nse.Client {
+	defaults := []nse.Option{
+		nse.WithBaseURL(s.URL),
+		nse.WithRateLimit(nse.GroupDefault, nse.RateLimit{}),
+		nse.WithRetry(nse.RetryPolicy{}),
+	}
+	return nse.NewClient(append(defaults, opts...)...)
+}
+
+// SetQuote serves v, marshalled to JSON, for /api/quote-equity?symbol=symbol
+func (s *Server) SetQuote(symbol string, v any) {
+	s.set(s.quotes, symbol, v)
+}
+
+// SetTradeInfo serves v for /api/quote-equity?symbol=symbol&section=trade_info
+func (s *Server) SetTradeInfo(symbol string, v any) {
+	s.set(s.tradeInfo, symbol, v)
+}
+
+// SetCorporateInfo serves v for /api/quote-equity?symbol=symbol&section=corp_info
+func (s *Server) SetCorporateInfo(symbol string, v any) {
+	s.set(s.corpInfo, symbol, v)
+}
+
+// SetIndexSnapshot serves v for /api/equity-stockIndices?index=indexName
+func (s *Server) SetIndexSnapshot(indexName string, v any) {
+	s.set(s.indices, indexName, v)
+}
+
+// SetAllIndices serves v for /api/allIndices
+func (s *Server) SetAllIndices(v any) {
+	data := mustMarshal(v)
+	s.mu.Lock()
+	s.allIndices = data
+	s.mu.Unlock()
+}
+
+// SetChart serves v for /api/chart-databyindex?index=identifier, or for the
+// pre-open chart when preopen is true
+func (s *Server) SetChart(identifier string, preopen bool, v any) {
+	if preopen {
+		s.set(s.preOpenCharts, identifier, v)
+		return
+	}
+	s.set(s.charts, identifier, v)
+}
+
+// SetPreOpen serves v for /api/market-data-pre-open
+func (s *Server) SetPreOpen(v any) {
+	data := mustMarshal(v)
+	s.mu.Lock()
+	s.preOpen = data
+	s.mu.Unlock()
+}
+
+// SetHistory replaces the daily history served for symbol. The CHTimestamp
+// of each row is used to filter by date range.
+func (s *Server) SetHistory(symbol string, rows []nse.EquityHistoricalInfo) {
+	s.mu.Lock()
+	s.history[strings.ToUpper(symbol)] = rows
+	s.mu.Unlock()
+}
+
+// SetIndexHistory replaces the daily history served for the index indexName,
+// e.g. "NIFTY 50". The Time of each candle is used to filter by date range
+// and its Volume and Value are served as the turnover of the day.
+func (s *Server) SetIndexHistory(indexName string, candles nse.Candles) {
+	s.mu.Lock()
+	s.indexHistory[strings.ToUpper(indexName)] = candles
+	s.mu.Unlock()
+}
+
+// SetHolidays serves v for /api/holiday-master
+func (s *Server) SetHolidays(v any) {
+	data := mustMarshal(v)
+	s.mu.Lock()
+	s.holidays = data
+	s.mu.Unlock()
+}
+
+// Fail queues failures for requests to path, such as "/api/quote-equity".
+// Each failure answers one request; once the queue is empty requests are
+// served normally again.
+func (s *Server) Fail(path string, failures ...Failure) {
+	s.mu.Lock()
+	s.failures[path] = append(s.failures[path], failures...)
+	s.mu.Unlock()
+}
+
+// ExpireSession invalidates all cookies handed out so far, so the next /api
+// request is answered with 401
+func (s *Server) ExpireSession() {
+	s.mu.Lock()
+	s.generation++
+	s.mu.Unlock()
+}
+
+// Requests returns how many requests were made to path
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func (s *Server) set(m map[string]json.RawMessage, key string, v any) {
+	data := mustMarshal(v)
+	s.mu.Lock()
+	m[strings.ToUpper(key)] = data
+	s.mu.Unlock()
+}
+
+func mustMarshal(v any) json.RawMessage {
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("nsetest: " + err.Error())
+	}
+	return data
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	var failure *Failure
+	if queue := s.failures[r.URL.Path]; len(queue) > 0 {
+		failure = &queue[0]
+		s.failures[r.URL.Path] = queue[1:]
+	}
+	generation := strconv.Itoa(s.generation)
+	s.mu.Unlock()
+
+	if failure != nil {
+		if failure.Delay > 0 {
+			select {
+			case <-time.After(failure.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if failure.Status != 0 {
+			for k, v := range failure.Header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(failure.Status)
+			w.Write([]byte(failure.Body))
+			return
+		}
+	}
+
+	if r.URL.Path == "/" {
+		http.SetCookie(w, &http.Cookie{Name: sessionCookie, Value: generation, Path: "/", MaxAge: 7200, HttpOnly: true})
+		http.SetCookie(w, &http.Cookie{Name: "bm_sv", Value: "nsetest", Path: "/", MaxAge: 7200})
+		w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+		w.Write([]byte("<!DOCTYPE html><html><head><title>NSE</title></head><body></body></html>"))
+		return
+	}
+
+	if cookie, err := r.Cookie(sessionCookie); err != nil || cookie.Value != generation {
+		writeJSON(w, http.StatusUnauthorized, json.RawMessage(`{"msg":"Unauthorized"}`))
+		return
+	}
+
+	query := r.URL.Query()
+	switch r.URL.Path {
+	case "/api/quote-equity":
+		symbol := strings.ToUpper(query.Get("symbol"))
+		switch query.Get("section") {
+		case "trade_info":
+			s.serveKey(w, s.tradeInfo, symbol)
+		case "corp_info":
+			s.serveKey(w, s.corpInfo, symbol)
+		default:
+			s.serveKey(w, s.quotes, symbol)
+		}
+	case "/api/equity-stockIndices":
+		s.serveKey(w, s.indices, query.Get("index"))
+	case "/api/allIndices":
+		s.mu.Lock()
+		data := s.allIndices
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, data)
+	case "/api/market-data-pre-open":
+		s.mu.Lock()
+		data := s.preOpen
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, data)
+	case "/api/chart-databyindex":
+		if query.Get("preopen") == "true" {
+			s.serveKey(w, s.preOpenCharts, query.Get("index"))
+			return
+		}
+		s.serveKey(w, s.charts, query.Get("index"))
+	case "/api/historical/cm/equity":
+		s.serveHistory(w, query.Get("symbol"), query.Get("series"), query.Get("from"), query.Get("to"))
+	case "/api/holiday-master":
+		s.mu.Lock()
+		data := s.holidays
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, data)
+	case "/api/historical/indicesHistory":
+		s.serveIndexHistory(w, query.Get("indexType"), query.Get("from"), query.Get("to"))
+	default:
+		writeJSON(w, http.StatusNotFound, json.RawMessage(`{"msg":"Not Found"}`))
+	}
+}
+
+// serveKey serves m[key], or an empty object as NSE does for unknown symbols
+func (s *Server) serveKey(w http.ResponseWriter, m map[string]json.RawMessage, key string) {
+	s.mu.Lock()
+	data, ok := m[strings.ToUpper(key)]
+	s.mu.Unlock()
+	if !ok {
+		data = json.RawMessage(`{}`)
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// nseDate is the dd-mm-yyyy format of the from/to query parameters
+const nseDate = "02-01-2006"
+
+func (s *Server) serveHistory(w http.ResponseWriter, symbol, series, from, to string) {
+	start, err1 := time.ParseInLocation(nseDate, from, nse.IST)
+	end, err2 := time.ParseInLocation(nseDate, to, nse.IST)
+	if err1 != nil || err2 != nil {
+		writeJSON(w, http.StatusBadRequest, json.RawMessage(`{"error":"Invalid date format, expected dd-mm-yyyy"}`))
+		return
+	}
+
+	// series arrives as ["EQ"]
+	var seriesList []string
+	if err := json.Unmarshal([]byte(series), &seriesList); err != nil || len(seriesList) == 0 {
+		seriesList = []string{"EQ"}
+	}
+
+	s.mu.Lock()
+	var rows []nse.EquityHistoricalInfo
+	for _, row := range s.history[strings.ToUpper(symbol)] {
+		day := row.CHTimestamp
+		if day.IsZero() || day.Before(start) || day.After(end) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	s.mu.Unlock()
+
+	// NSE returns the newest day first
+	sort.Slice(rows, func(i, j int) bool { return rows[i].CHTimestamp.After(rows[j].CHTimestamp.Time) })
+	if rows == nil {
+		rows = []nse.EquityHistoricalInfo{}
+	}
+
+	var response nse.EquityHistoricalData
+	response.Data = rows
+	response.Meta.Series = seriesList
+	response.Meta.FromDate = nse.Date{Time: start}
+	response.Meta.ToDate = nse.Date{Time: end}
+	response.Meta.Symbols = []string{strings.ToUpper(symbol)}
+	writeJSON(w, http.StatusOK, mustMarshal(response))
+}
+
+func (s *Server) serveIndexHistory(w http.ResponseWriter, indexName, from, to string) {
+	start, err1 := time.ParseInLocation(nseDate, from, nse.IST)
+	end, err2 := time.ParseInLocation(nseDate, to, nse.IST)
+	if err1 != nil || err2 != nil {
+		writeJSON(w, http.StatusBadRequest, json.RawMessage(`{"error":"Invalid date format, expected dd-mm-yyyy"}`))
+		return
+	}
+
+	type closeRecord struct {
+		Name  string  `json:"EOD_INDEX_NAME"`
+		Open  float64 `json:"EOD_OPEN_INDEX_VAL"`
+		High  float64 `json:"EOD_HIGH_INDEX_VAL"`
+		Close float64 `json:"EOD_CLOSE_INDEX_VAL"`
+		Low   float64 `json:"EOD_LOW_INDEX_VAL"`
+		Date  string  `json:"EOD_TIMESTAMP"`
+	}
+	type turnoverRecord struct {
+		Name     string  `json:"HIT_INDEX_NAME_UPPER"`
+		Date     string  `json:"HIT_TIMESTAMP"`
+		Qty      int64   `json:"HIT_TRADED_QTY"`
+		Turnover float64 `json:"HIT_TURN_OVER"`
+	}
+	var response struct {
+		Data struct {
+			Closes    []closeRecord    `json:"indexCloseOnlineRecords"`
+			Turnovers []turnoverRecord `json:"indexTurnoverRecords"`
+		} `json:"data"`
+	}
+	response.Data.Closes = []closeRecord{}
+	response.Data.Turnovers = []turnoverRecord{}
+
+	name := strings.ToUpper(indexName)
+	s.mu.Lock()
+	candles := s.indexHistory[name]
+	s.mu.Unlock()
+
+	// NSE returns the newest day first, with dates like 15-MAR-2024
+	for i := len(candles) - 1; i >= 0; i-- {
+		c := candles[i]
+		if c.Time.Before(start) || c.Time.After(end) {
+			continue
+		}
+		date := strings.ToUpper(c.Time.In(nse.IST).Format("02-Jan-2006"))
+		response.Data.Closes = append(response.Data.Closes, closeRecord{name, c.Open, c.High, c.Close, c.Low, date})
+		response.Data.Turnovers = append(response.Data.Turnovers, turnoverRecord{name, date, c.Volume, c.Value})
+	}
+	writeJSON(w, http.StatusOK, mustMarshal(response))
+}
+
+func writeJSON(w http.ResponseWriter, status int, data json.RawMessage) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(data)
+}
@@ -0,0 +1,328 @@
+package nsetest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"nse/lib/nse"
+	"nse/lib/nse/nsetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtures(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	quote, err := client.QuoteEquity(ctx, "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, "MITCONEQN", quote.Info.Identifier)
+
+	tradeInfo, err := client.QuoteEquityTradeInfo(ctx, "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, nse.Int(48213), tradeInfo.MarketDeptOrderBook.TradeInfo.TotalTradedVolume)
+
+	symbols, err := client.GetSymbols(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TATATECH", "ZEEMEDIA", "MITCON"}, symbols)
+
+	info, err := client.CorporateInfo(ctx, "MITCON")
+	require.NoError(t, err)
+	assert.Len(t, info.Corporate.Announcements, 3)
+	assert.Equal(t, "Bigshare Services Pvt. Ltd.", info.Corporate.TransferAgentDetail[0].Office)
+
+	chart, err := client.ChartDataByIndex(ctx, "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, nse.Float(98.45), chart.ClosePrice)
+	assert.NotEmpty(t, chart.GraphData)
+
+	_, err = client.QuoteEquity(ctx, "NOSUCH")
+	assert.ErrorIs(t, err, nse.ErrSymbolNotFound)
+
+	assert.Equal(t, 1, server.Requests("/"))
+}
+
+func TestSeededQuote(t *testing.T) {
+	server := nsetest.NewServer(t)
+
+	var quote nse.EquityDetails
+	quote.Info.Symbol = "ACME"
+	quote.Info.CompanyName = "Acme Industries Limited"
+	quote.PriceInfo.LastPrice = 42.5
+	server.SetQuote("ACME", quote)
+
+	got, err := server.Client().QuoteEquity(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Industries Limited", got.Info.CompanyName)
+	assert.Equal(t, nse.Float(42.5), got.PriceInfo.LastPrice)
+}
+
+func TestInjectedFailures(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	server.Fail("/api/quote-equity", nsetest.RateLimited(time.Second))
+	_, err := client.QuoteEquity(ctx, "MITCON")
+	assert.ErrorIs(t, err, nse.ErrRateLimited)
+
+	server.Fail("/api/quote-equity", nsetest.Unavailable())
+	_, err = client.QuoteEquity(ctx, "MITCON")
+	assert.ErrorIs(t, err, nse.ErrUpstreamUnavailable)
+
+	server.Fail("/api/quote-equity", nsetest.MalformedJSON())
+	_, err = client.QuoteEquity(ctx, "MITCON")
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	server.Fail("/api/quote-equity", nsetest.Slow(time.Second))
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = client.QuoteEquity(timeout, "MITCON")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the failures are used up
+	_, err = client.QuoteEquity(ctx, "MITCON")
+	assert.NoError(t, err)
+}
+
+func TestSessionRefresh(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	_, err := client.QuoteEquity(ctx, "MITCON")
+	require.NoError(t, err)
+
+	server.ExpireSession()
+	_, err = client.QuoteEquity(ctx, "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.Requests("/"))
+
+	server.Fail("/api/quote-equity", nsetest.Unauthorized(), nsetest.Unauthorized())
+	_, err = client.QuoteEquity(ctx, "MITCON")
+	assert.ErrorIs(t, err, nse.ErrUnauthorized)
+}
+
+func TestHomePageForbidden(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	server.Fail("/", nsetest.Failure{Status: http.StatusForbidden, Body: "Access Denied"})
+	_, err := client.QuoteEquity(ctx, "MITCON")
+	assert.ErrorIs(t, err, nse.ErrUnauthorized)
+	var apiErr *nse.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, "/", apiErr.Endpoint)
+	}
+	assert.Zero(t, server.Requests("/api/quote-equity"))
+
+	// the refused session is not cached
+	_, err = client.QuoteEquity(ctx, "MITCON")
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.Requests("/"))
+}
+
+func TestHistoricalEndpoint(t *testing.T) {
+	server := nsetest.NewServer(t)
+
+	response, err := http.Get(server.URL + "/")
+	require.NoError(t, err)
+	response.Body.Close()
+
+	request, _ := http.NewRequest("GET", server.URL+`/api/historical/cm/equity?symbol=MITCON&series=["EQ"]&from=12-03-2024&to=14-03-2024`, nil)
+	for _, cookie := range response.Cookies() {
+		request.AddCookie(cookie)
+	}
+	response, err = http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	var data nse.EquityHistoricalData
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&data))
+	require.Len(t, data.Data, 3)
+	assert.Equal(t, "2024-03-14", data.Data[0].CHTimestamp.Format("2006-01-02"))
+	assert.Equal(t, "2024-03-12", data.Data[2].CHTimestamp.Format("2006-01-02"))
+}
+
+func TestEquityHistory(t *testing.T) {
+	server := nsetest.NewServer(t)
+	server.Fail("/api/historical/cm/equity", nsetest.Slow(10*time.Millisecond))
+
+	history, err := server.Client().EquityHistory(context.Background(), "MITCON", &nse.DateRange{
+		Start: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+	assert.Equal(t, "2024-03-11", history[0].CHTimestamp.Format("2006-01-02"))
+	assert.Equal(t, "2024-03-15", history[4].CHTimestamp.Format("2006-01-02"))
+}
+
+func TestEquityHistoryChunksResume(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+	server.Fail("/api/historical/cm/equity", nsetest.Unavailable())
+
+	ranges := []nse.DateRange{{
+		Start: time.Date(2024, 1, 1, 0, 0, 0, 0, nse.IST),
+		End:   time.Date(2024, 3, 31, 0, 0, 0, 0, nse.IST),
+	}}
+	result, err := client.EquityHistoryChunks(ctx, "MITCON", ranges, nse.FetchOptions{Parallelism: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Data, 1)
+	gaps := result.Gaps()
+	require.Len(t, gaps, 1)
+	assert.True(t, gaps[0].Start.Equal(ranges[0].Start))
+
+	result, err = client.EquityHistoryChunks(ctx, "MITCON", gaps, nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed)
+	assert.Len(t, result.Data, 1)
+}
+
+func TestIndexHistory(t *testing.T) {
+	server := nsetest.NewServer(t)
+
+	candles, err := server.Client().IndexHistory(context.Background(), "NIFTY 50", nse.DateRange{
+		Start: time.Date(2024, 3, 12, 0, 0, 0, 0, nse.IST),
+		End:   time.Date(2024, 3, 31, 0, 0, 0, 0, nse.IST),
+	})
+	require.NoError(t, err)
+	require.Len(t, candles, 4)
+	assert.True(t, candles[0].Time.Equal(time.Date(2024, 3, 12, 0, 0, 0, 0, nse.IST)))
+	assert.Equal(t, 22023.35, candles[3].Close)
+	assert.Equal(t, int64(579430000), candles[3].Volume)
+}
+
+func TestIndexSnapshot(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	snapshot, err := client.IndexSnapshot(ctx, "NIFTY BANK")
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Constituents(), 12)
+
+	var custom nse.IndexDetails
+	custom.Name = "NIFTY ALPHA"
+	custom.Data = []nse.IndexEquityInfo{{Priority: 1, Symbol: "NIFTY ALPHA"}, {Symbol: "ACME", PChange: 2.5}}
+	server.SetIndexSnapshot("NIFTY ALPHA", custom)
+	snapshot, err = client.IndexSnapshot(ctx, "nifty alpha")
+	require.NoError(t, err)
+	require.Len(t, snapshot.Constituents(), 1)
+	assert.Equal(t, nse.Float(2.5), snapshot.Constituents()[0].PChange)
+
+	_, err = client.IndexSnapshot(ctx, "NIFTY NOSUCH")
+	assert.ErrorIs(t, err, nse.ErrSymbolNotFound)
+}
+
+func TestAllIndices(t *testing.T) {
+	server := nsetest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	indices, err := client.AllIndices(ctx)
+	require.NoError(t, err)
+
+	// the indices listed fetch their snapshot and history
+	sectoral := indices.Category("sectoral")
+	require.Len(t, sectoral, 1)
+	bank := sectoral[0].Indices[0]
+	snapshot, err := bank.Snapshot(ctx, client)
+	require.NoError(t, err)
+	assert.Equal(t, bank.Last, snapshot.Metadata.Last)
+
+	broad := indices.Category("broad market")
+	require.Len(t, broad, 1)
+	nifty := broad[0].Indices[0]
+	candles, err := nifty.History(ctx, client, nse.DateRange{
+		Start: time.Date(2024, 3, 15, 0, 0, 0, 0, nse.IST),
+		End:   time.Date(2024, 3, 15, 0, 0, 0, 0, nse.IST),
+	})
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.Equal(t, float64(nifty.Last), candles[0].Close)
+
+	server.SetAllIndices(nse.AllIndicesData{Data: []nse.IndexQuote{{Key: "STRATEGY INDICES", Index: "NIFTY ALPHA 50"}}})
+	indices, err = client.AllIndices(ctx)
+	require.NoError(t, err)
+	require.Len(t, indices.Categories(), 1)
+	assert.Equal(t, "NIFTY ALPHA 50", indices.Categories()[0].Indices[0].Index)
+}
+
+func TestIndexChart(t *testing.T) {
+	server := nsetest.NewServer(t)
+	open := time.Date(2024, 3, 15, 9, 15, 0, 0, nse.IST)
+	server.SetChart("NIFTY 50", false, nse.IntradayData{
+		Identifier: "NIFTY 50",
+		Name:       "NIFTY 50",
+		GraphData:  nse.Ticks{{Time: open, Price: 22064.85}, {Time: open.Add(time.Minute), Price: 22071.2}},
+	})
+
+	chart, err := server.Client().IntradayChart(context.Background(), "NIFTY 50", false)
+	require.NoError(t, err)
+	require.Len(t, chart.GraphData, 2)
+	assert.True(t, chart.GraphData[1].Time.Equal(open.Add(time.Minute)))
+
+	_, err = server.Client().IntradayChart(context.Background(), "NIFTY NOSUCH", false)
+	assert.ErrorIs(t, err, nse.ErrSymbolNotFound)
+	assert.Zero(t, server.Requests("/api/quote-equity"))
+}
+
+func TestTradingCalendar(t *testing.T) {
+	server := nsetest.NewServer(t)
+	server.SetHolidays(map[string]any{"CM": []map[string]string{
+		{"tradingDate": "15-Mar-2024", "description": "Exchange holiday"},
+	}})
+	client := server.Client()
+
+	cal, err := client.TradingCalendar(context.Background())
+	require.NoError(t, err)
+	friday := time.Date(2024, 3, 15, 0, 0, 0, 0, nse.IST)
+	assert.False(t, cal.IsTradingDay(friday))
+	assert.Len(t, cal.Holidays(), 1)
+
+	// the history of a range without trading days needs no requests
+	history, err := server.Client(nse.WithCalendar(cal)).EquityHistory(context.Background(), "MITCON", &nse.DateRange{
+		Start: friday,
+		End:   friday.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	assert.Zero(t, server.Requests("/api/historical/cm/equity"))
+
+	// without WithCalendar the client fetches the holidays itself, once
+	client = server.Client()
+	for i := 0; i < 2; i++ {
+		history, err = client.EquityHistory(context.Background(), "MITCON", &nse.DateRange{
+			Start: friday,
+			End:   friday.AddDate(0, 0, 2),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, history)
+	}
+	assert.Zero(t, server.Requests("/api/historical/cm/equity"))
+	assert.Equal(t, 2, server.Requests("/api/holiday-master"))
+
+	// if they cannot be fetched the built-in ones are used, and the failure
+	// is remembered rather than fetched again on every download
+	server.Fail("/api/holiday-master", nsetest.Unavailable(), nsetest.Unavailable())
+	client = server.Client()
+	for i := 0; i < 2; i++ {
+		history, err = client.EquityHistory(context.Background(), "MITCON", &nse.DateRange{
+			Start: friday,
+			End:   friday.AddDate(0, 0, 2),
+		})
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	}
+	assert.Equal(t, 3, server.Requests("/api/holiday-master"))
+}
@@ -0,0 +1,129 @@
+package nse
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointGroup identifies a family of NSE endpoints that share a rate limit
+type EndpointGroup string
+
+const (
+	// GroupDefault covers the cookie handshake and every endpoint without a group of its own.
+	// Its limit is shared by all groups that are not configured separately.
+	GroupDefault    EndpointGroup = "default"
+	GroupQuote      EndpointGroup = "quote"
+	GroupMarketData EndpointGroup = "market-data"
+	GroupChart      EndpointGroup = "chart"
+	GroupHistorical EndpointGroup = "historical"
+)
+
+// endpointGroups maps endpoint path prefixes to their group
+var endpointGroups = []struct {
+	prefix string
+	group  EndpointGroup
+}{
+	{"/api/quote-equity", GroupQuote},
+	{"/api/market-data-pre-open", GroupMarketData},
+	{"/api/chart-databyindex", GroupChart},
+	{"/api/historical/", GroupHistorical},
+}
+
+// defaultRateLimit is what NSE tolerates from a single session without blocking it
+var defaultRateLimit = RateLimit{Rate: 3, Burst: 3}
+
+// groupOf returns the endpoint group of an endpoint path
+func groupOf(endpoint string) EndpointGroup {
+	for _, g := range endpointGroups {
+		if strings.HasPrefix(endpoint, g.prefix) {
+			return g.group
+		}
+	}
+	return GroupDefault
+}
+
+// RateLimit is a token bucket allowing Rate requests per second on average
+// and bursts of up to Burst requests. The zero value means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// WithRateLimit sets the rate limit of an endpoint group. Groups without a
+// limit of their own share the GroupDefault bucket.
+func WithRateLimit(group EndpointGroup, limit RateLimit) Option {
+	return func(o *options) {
+		o.rateLimits[group] = limit
+	}
+}
+
+// limiter is a token bucket
+type limiter struct {
+	mu     sync.Mutex
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &limiter{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// wait blocks until a token is available or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil || l.limit.Rate <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.limit.Rate
+	if burst := float64(l.limit.Burst); l.tokens > burst {
+		l.tokens = burst
+	}
+	l.last = now
+	// take the token now, possibly going negative, so that waiters queue up
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.limit.Rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the token back
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// limiters holds one token bucket per configured endpoint group
+type limiters map[EndpointGroup]*limiter
+
+func newLimiters(limits map[EndpointGroup]RateLimit) limiters {
+	l := make(limiters, len(limits))
+	for group, limit := range limits {
+		l[group] = newLimiter(limit)
+	}
+	return l
+}
+
+// forEndpoint returns the limiter governing endpoint
+func (l limiters) forEndpoint(endpoint string) *limiter {
+	if lim, ok := l[groupOf(endpoint)]; ok {
+		return lim
+	}
+	return l[GroupDefault]
+}
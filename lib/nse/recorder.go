@@ -0,0 +1,152 @@
+package nse
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ResponseRecorder writes every raw request and response of a client to Dir,
+// for debugging. Files are named <endpoint>_<symbol>_<timestamp>.http and
+// contain the request and the response in HTTP/1.1 wire format, with the
+// session cookies redacted: the Cookie header, and the values of the
+// Set-Cookie headers.
+type ResponseRecorder struct {
+	Dir string
+	// Gzip compresses the files, adding a .gz extension
+	Gzip bool
+	// MaxBodySize truncates response bodies to this many bytes; 0 means no limit
+	MaxBodySize int
+
+	seq atomic.Uint64
+}
+
+// WithResponseRecorder records every request and response of the client with r
+func WithResponseRecorder(r *ResponseRecorder) Option {
+	return func(o *options) {
+		o.recorder = r
+	}
+}
+
+// record writes one exchange. Errors are returned for logging only; a
+// recorder failure never fails the request.
+func (r *ResponseRecorder) record(response *resty.Response) error {
+	if r == nil || response == nil || response.RawResponse == nil {
+		return nil
+	}
+	request := response.Request.RawRequest
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", request.Method, request.URL.RequestURI())
+	fmt.Fprintf(&buf, "Host: %s\r\n", request.URL.Host)
+	writeHeader(&buf, request.Header)
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(&buf, "HTTP/1.1 %s\r\n", response.Status())
+	writeHeader(&buf, response.Header())
+	buf.WriteString("\r\n")
+	body := response.Body()
+	if r.MaxBodySize > 0 && len(body) > r.MaxBodySize {
+		body = body[:r.MaxBodySize]
+	}
+	buf.Write(body)
+
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return err
+	}
+	name := r.fileName(request.URL, response.ReceivedAt())
+	data := buf.Bytes()
+	if r.Gzip {
+		var zbuf bytes.Buffer
+		zw := gzip.NewWriter(&zbuf)
+		zw.Write(data)
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		name += ".gz"
+		data = zbuf.Bytes()
+	}
+	return os.WriteFile(filepath.Join(r.Dir, name), data, 0644)
+}
+
+// fileName builds <endpoint>_<symbol>_<timestamp>.http for u
+func (r *ResponseRecorder) fileName(u *url.URL, at time.Time) string {
+	endpoint := strings.Trim(strings.TrimPrefix(u.Path, "/api"), "/")
+	if endpoint == "" {
+		endpoint = "home"
+	}
+	query := u.Query()
+	if section := query.Get("section"); section != "" {
+		endpoint += "-" + section
+	}
+	if query.Get("preopen") == "true" {
+		endpoint += "-preopen"
+	}
+
+	symbol := query.Get("symbol")
+	if symbol == "" {
+		symbol = query.Get("index")
+	}
+	if symbol == "" {
+		symbol = query.Get("indexType")
+	}
+	if symbol == "" {
+		symbol = "-"
+	}
+
+	// the sequence number keeps concurrent responses received in the same instant apart
+	return fmt.Sprintf("%s_%s_%s-%d.http", sanitize(endpoint), sanitize(symbol),
+		at.Format("20060102T150405.000000000"), r.seq.Add(1))
+}
+
+// sanitize makes s safe to use in a file name
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+func writeHeader(buf *bytes.Buffer, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			switch {
+			case strings.EqualFold(k, "Cookie"):
+				v = "[redacted]"
+			case strings.EqualFold(k, "Set-Cookie"):
+				v = redactSetCookie(v)
+			}
+			fmt.Fprintf(buf, "%s: %s\r\n", k, v)
+		}
+	}
+}
+
+// redactSetCookie replaces the value of a Set-Cookie header, keeping the
+// cookie name and attributes
+func redactSetCookie(v string) string {
+	cookie, attributes, _ := strings.Cut(v, ";")
+	name, _, _ := strings.Cut(cookie, "=")
+	v = name + "=[redacted]"
+	if attributes != "" {
+		v += ";" + attributes
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package nse
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.SetCookie(w, &http.Cookie{Name: "nsit", Value: "secret", Path: "/", HttpOnly: true})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"info":{"symbol":"MITCON","companyName":"MITCON Consultancy"}}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder := &ResponseRecorder{Dir: dir, MaxBodySize: 16}
+	c := NewClient(WithBaseURL(server.URL), WithResponseRecorder(recorder))
+	_, err := c.QuoteEquity(context.Background(), "MITCON")
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "quote-equity_MITCON_*.http"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	home, _ := filepath.Glob(filepath.Join(dir, "home_-_*.http"))
+	require.Len(t, home, 1)
+
+	// the session handed out is redacted too
+	data, err := os.ReadFile(home[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Set-Cookie: nsit=[redacted]; Path=/; HttpOnly\r\n")
+	assert.NotContains(t, string(data), "secret")
+
+	data, err = os.ReadFile(files[0])
+	require.NoError(t, err)
+	dump := string(data)
+	assert.True(t, strings.HasPrefix(dump, "GET /api/quote-equity?symbol=MITCON HTTP/1.1\r\n"))
+	assert.Contains(t, dump, "Cookie: [redacted]\r\n")
+	assert.NotContains(t, dump, "secret")
+	assert.Contains(t, dump, "HTTP/1.1 200 OK\r\n")
+	assert.True(t, strings.HasSuffix(dump, "\r\n\r\n"+`{"info":{"symbol`))
+}
+
+func TestResponseRecorderGzip(t *testing.T) {
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	c := NewClient(WithBaseURL(server.URL), WithResponseRecorder(&ResponseRecorder{Dir: dir, Gzip: true}))
+	_, err := c.MarketDataPreOpen(context.Background())
+	require.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "market-data-pre-open_-_*.http.gz"))
+	require.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(data), `{"data":[]}`))
+}
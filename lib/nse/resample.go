@@ -0,0 +1,107 @@
+package nse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Period is a calendar period daily candles are resampled into
+type Period int
+
+const (
+	Daily Period = iota
+	// Weekly periods run from Monday to Sunday
+	Weekly
+	Monthly
+	// Quarterly periods start in January, April, July and October, which are
+	// also the quarters of the Indian fiscal year
+	Quarterly
+	Yearly
+	// FiscalYearly periods are Indian fiscal years, from April to March
+	FiscalYearly
+)
+
+var periodNames = [...]string{"day", "week", "month", "quarter", "year", "fiscal-year"}
+
+func (p Period) String() string {
+	if p < 0 || int(p) >= len(periodNames) {
+		return fmt.Sprintf("Period(%d)", int(p))
+	}
+	return periodNames[p]
+}
+
+// ParsePeriod parses a period name as returned by Period.String, or one of
+// its aliases: "1d", "daily", "1w", "weekly", "1mo", "monthly", "1q",
+// "quarterly", "1y", "yearly" and "fy"
+func ParsePeriod(s string) (Period, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "day", "daily", "1d", "d":
+		return Daily, nil
+	case "week", "weekly", "1w", "w":
+		return Weekly, nil
+	case "month", "monthly", "1mo", "mo":
+		return Monthly, nil
+	case "quarter", "quarterly", "1q", "q":
+		return Quarterly, nil
+	case "year", "yearly", "1y", "y":
+		return Yearly, nil
+	case "fiscal-year", "fiscal", "fy":
+		return FiscalYearly, nil
+	}
+	return 0, fmt.Errorf("nse: unknown period %q", s)
+}
+
+// start returns midnight IST of the first day of the period holding t
+func (p Period) start(t time.Time) time.Time {
+	t = t.In(IST)
+	year, month, day := t.Date()
+	switch p {
+	case Weekly:
+		day -= (int(t.Weekday()) + 6) % 7
+	case Monthly:
+		day = 1
+	case Quarterly:
+		month, day = (month-1)/3*3+1, 1
+	case Yearly:
+		month, day = time.January, 1
+	case FiscalYearly:
+		if month < time.April {
+			year--
+		}
+		month, day = time.April, 1
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, IST)
+}
+
+// Resample aggregates daily candles into bars of period p: the open of the
+// first candle, the highest high, the lowest low, the close of the last
+// candle, the sum of volume, value and trades, and the volume-weighted VWAP.
+// PrevClose is that of the first candle. A bar is timed at its first candle,
+// so a week starting on a holiday is timed at the next trading day. Periods
+// without candles have no bar.
+func (cs Candles) Resample(p Period) Candles {
+	var bars Candles
+	var start time.Time
+	var weighted float64
+	for _, c := range cs {
+		if s := p.start(c.Time); len(bars) == 0 || !s.Equal(start) {
+			start, weighted = s, 0
+			bars = append(bars, Candle{
+				Time: c.Time, Open: c.Open, High: c.High, Low: c.Low, PrevClose: c.PrevClose,
+			})
+		}
+		bar := &bars[len(bars)-1]
+		bar.High = max(bar.High, c.High)
+		bar.Low = min(bar.Low, c.Low)
+		bar.Close = c.Close
+		bar.Volume += c.Volume
+		bar.Value += c.Value
+		bar.Trades += c.Trades
+		weighted += c.VWAP * float64(c.Volume)
+		if bar.Volume > 0 {
+			bar.VWAP = weighted / float64(bar.Volume)
+		}
+	}
+	return bars
+}
@@ -0,0 +1,69 @@
+package nse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResample(t *testing.T) {
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, IST) }
+	candles := Candles{
+		// Monday 25 March was a holiday
+		{Time: day(3, 26), Open: 10, High: 12, Low: 9, Close: 11, PrevClose: 9.5, Volume: 100, Value: 1050, Trades: 5, VWAP: 10.5},
+		{Time: day(3, 27), Open: 11, High: 14, Low: 10, Close: 13, PrevClose: 11, Volume: 300, Value: 3750, Trades: 7, VWAP: 12.5},
+		{Time: day(3, 28), Open: 13, High: 13, Low: 8, Close: 9, PrevClose: 13, Volume: 0},
+		{Time: day(4, 1), Open: 9, High: 10, Low: 9, Close: 10, PrevClose: 9, Volume: 200, Value: 1900, Trades: 3, VWAP: 9.5},
+	}
+
+	weekly := candles.Resample(Weekly)
+	require.Len(t, weekly, 2)
+	assert.Equal(t, Candle{
+		Time: day(3, 26), Open: 10, High: 14, Low: 8, Close: 9, PrevClose: 9.5,
+		Volume: 400, Value: 4800, Trades: 12, VWAP: 12,
+	}, weekly[0])
+	assert.Equal(t, candles[3], weekly[1])
+
+	monthly := candles.Resample(Monthly)
+	require.Len(t, monthly, 2)
+	assert.True(t, monthly[1].Time.Equal(day(4, 1)))
+
+	assert.Len(t, candles.Resample(Quarterly), 2)
+	assert.Len(t, candles.Resample(Yearly), 1)
+	assert.Len(t, candles.Resample(FiscalYearly), 2)
+	assert.Equal(t, candles, candles.Resample(Daily))
+	assert.Empty(t, Candles(nil).Resample(Weekly))
+}
+
+func TestPeriodStart(t *testing.T) {
+	at := time.Date(2024, 2, 15, 14, 30, 0, 0, IST)
+	tests := map[Period]time.Time{
+		Daily:        time.Date(2024, 2, 15, 0, 0, 0, 0, IST),
+		Weekly:       time.Date(2024, 2, 12, 0, 0, 0, 0, IST),
+		Monthly:      time.Date(2024, 2, 1, 0, 0, 0, 0, IST),
+		Quarterly:    time.Date(2024, 1, 1, 0, 0, 0, 0, IST),
+		Yearly:       time.Date(2024, 1, 1, 0, 0, 0, 0, IST),
+		FiscalYearly: time.Date(2023, 4, 1, 0, 0, 0, 0, IST),
+	}
+	for p, want := range tests {
+		assert.True(t, p.start(at).Equal(want), p.String())
+	}
+	// Sunday belongs to the week started by the Monday before
+	assert.True(t, Weekly.start(time.Date(2024, 2, 18, 0, 0, 0, 0, IST)).Equal(time.Date(2024, 2, 12, 0, 0, 0, 0, IST)))
+	assert.True(t, FiscalYearly.start(time.Date(2024, 4, 1, 0, 0, 0, 0, IST)).Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, IST)))
+}
+
+func TestParsePeriod(t *testing.T) {
+	for s, want := range map[string]Period{"day": Daily, "1w": Weekly, "Monthly": Monthly, "q": Quarterly, "year": Yearly, "fy": FiscalYearly} {
+		p, err := ParsePeriod(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, p, s)
+		back, err := ParsePeriod(p.String())
+		require.NoError(t, err)
+		assert.Equal(t, p, back)
+	}
+	_, err := ParsePeriod("fortnight")
+	assert.Error(t, err)
+}
@@ -0,0 +1,125 @@
+package nse
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// RetryPolicy controls how transient failures (429, 5xx and network errors)
+// are retried. Delays grow exponentially from BaseDelay up to MaxDelay with
+// full jitter, unless NSE asks for a specific delay with Retry-After, which
+// is honoured up to MaxDelay.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+var defaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// WithRetry sets the retry policy. A zero RetryPolicy disables retries.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// Stats are counters of the requests made by a Client
+type Stats struct {
+	// Requests is the number of HTTP requests sent, including retries
+	Requests uint64
+	// Retries is the number of requests that were sent again after a transient failure
+	Retries uint64
+	// Drops is the number of requests given up after exhausting their retries
+	// or while waiting for the rate limiter or a backoff delay
+	Drops uint64
+}
+
+type stats struct {
+	requests atomic.Uint64
+	retries  atomic.Uint64
+	drops    atomic.Uint64
+}
+
+// Stats returns a snapshot of the client's request counters
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests: c.stats.requests.Load(),
+		Retries:  c.stats.retries.Load(),
+		Drops:    c.stats.drops.Load(),
+	}
+}
+
+// retryable reports whether a request that ended with response and err is
+// worth sending again
+func retryable(response *resty.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, ErrUpstreamUnavailable)
+	}
+	code := response.StatusCode()
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// backoff returns how long to wait before retry number attempt (starting at 0)
+func (p RetryPolicy) backoff(attempt int, response *resty.Response) time.Duration {
+	if response != nil {
+		if d, ok := retryAfter(response.Header().Get("Retry-After")); ok {
+			if p.MaxDelay > 0 && d > p.MaxDelay {
+				d = p.MaxDelay
+			}
+			return d
+		}
+	}
+
+	d := p.BaseDelay << uint(attempt)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
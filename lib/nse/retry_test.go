@@ -0,0 +1,94 @@
+package nse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransientFailures(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"data":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRetry(RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}))
+	_, err := c.MarketDataPreOpen(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Requests: 4, Retries: 2, Drops: 0}, c.Stats())
+}
+
+func TestRetryExhausted(t *testing.T) {
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRetry(RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond}))
+	_, err := c.MarketDataPreOpen(context.Background())
+	assert.ErrorIs(t, err, ErrUpstreamUnavailable)
+	assert.Equal(t, Stats{Requests: 3, Retries: 1, Drops: 1}, c.Stats())
+}
+
+func TestRetryAfter(t *testing.T) {
+	d, ok := retryAfter("7")
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, d)
+
+	_, ok = retryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(withSession(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRetry(RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.MarketDataPreOpen(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Stats{Requests: 3, Retries: 1, Drops: 0}, c.Stats())
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(RateLimit{Rate: 50, Burst: 2})
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		assert.NoError(t, l.wait(context.Background()))
+	}
+	// two from the burst, the other five at 20ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, l.wait(ctx), context.Canceled)
+}
+
+func TestGroupOf(t *testing.T) {
+	assert.Equal(t, GroupQuote, groupOf("/api/quote-equity?symbol=MITCON"))
+	assert.Equal(t, GroupHistorical, groupOf("/api/historical/cm/equity?symbol=MITCON"))
+	assert.Equal(t, GroupDefault, groupOf("/"))
+}
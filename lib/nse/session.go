@@ -0,0 +1,93 @@
+package nse
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// defaultSessionTTL is how long a session is reused when NSE does not send an
+// expiry for any of the required cookies
+const defaultSessionTTL = 10 * time.Minute
+
+// requiredCookies are the cookies NSE checks on its /api endpoints
+var requiredCookies = []string{"nsit", "nseappid", "ak_bmsc", "AKA_A2", "bm_mi", "bm_sv"}
+
+// session caches the NSE cookies together with the time they expire
+type session struct {
+	mu      sync.Mutex
+	cookie  string
+	expires time.Time
+}
+
+// get returns the cached cookie, calling fetch when there is none or it has expired
+func (s *session) get(ctx context.Context, fetch func(context.Context) (string, time.Time, error)) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cookie == "" || !time.Now().Before(s.expires) {
+		cookie, expires, err := fetch(ctx)
+		if err != nil {
+			return "", err
+		}
+		s.cookie, s.expires = cookie, expires
+	}
+	return s.cookie, nil
+}
+
+// invalidate drops the cached cookie if it is still the stale one, so that
+// concurrent callers hitting the same expired session refresh it only once
+func (s *session) invalidate(stale string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cookie == stale {
+		s.cookie = ""
+	}
+}
+
+// sessionCookie filters the required cookies and returns them as a Cookie
+// header together with the earliest expiry among them
+func sessionCookie(cookies []*http.Cookie) (string, time.Time) {
+	var cook []string
+	expires := time.Now().Add(defaultSessionTTL)
+
+	for _, cookie := range cookies {
+		if !slices.Contains(requiredCookies, cookie.Name) {
+			continue
+		}
+		cook = append(cook, cookie.Name+"="+cookie.Value)
+
+		var exp time.Time
+		switch {
+		case cookie.MaxAge > 0:
+			exp = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second)
+		case !cookie.Expires.IsZero():
+			exp = cookie.Expires
+		}
+		if !exp.IsZero() && exp.Before(expires) {
+			expires = exp
+		}
+	}
+
+	return strings.Join(cook, "; "), expires
+}
+
+// sessionExpired reports whether NSE rejected the session cookies, either
+// with a 401/403 or by serving its HTML bot-challenge page instead of JSON
+func sessionExpired(response *resty.Response) bool {
+	switch response.StatusCode() {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	if strings.Contains(response.Header().Get("Content-Type"), "text/html") {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(response.Body()), []byte("<"))
+}
@@ -0,0 +1,90 @@
+package nse
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withSession serves the NSE home page with a session cookie and passes every
+// other request to handler
+func withSession(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.SetCookie(w, &http.Cookie{Name: "nsit", Value: "test"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func TestSessionReused(t *testing.T) {
+	var handshakes, rejected int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			atomic.AddInt32(&handshakes, 1)
+			http.SetCookie(w, &http.Cookie{Name: "nsit", Value: "abc", MaxAge: 3600})
+			http.SetCookie(w, &http.Cookie{Name: "bm_sv", Value: "xyz"})
+			http.SetCookie(w, &http.Cookie{Name: "unrelated", Value: "1"})
+			return
+		}
+		if r.URL.Query().Get("reject") == "1" && atomic.CompareAndSwapInt32(&rejected, 0, 1) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>Access Denied</html>"))
+			return
+		}
+		assert.Equal(t, "nsit=abc; bm_sv=xyz", r.Header.Get("Cookie"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL))
+	for i := 0; i < 3; i++ {
+		body, err := c.get(context.Background(), "/api/test")
+		assert.NoError(t, err)
+		assert.Equal(t, "{}", string(body))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handshakes))
+
+	body, err := c.get(context.Background(), "/api/test?reject=1")
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", string(body))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&handshakes))
+}
+
+func TestSessionCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := NewClient(WithBaseURL(server.URL)).QuoteEquity(ctx, "MITCON")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSessionRefused(t *testing.T) {
+	for name, handler := range map[string]http.HandlerFunc{
+		"forbidden": func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "nsit", Value: "abc"})
+			w.WriteHeader(http.StatusForbidden)
+		},
+		"no cookies": func(w http.ResponseWriter, r *http.Request) {},
+	} {
+		server := httptest.NewServer(handler)
+		c := NewClient(WithBaseURL(server.URL))
+		_, err := c.getCookie(context.Background())
+		assert.ErrorIs(t, err, ErrUnauthorized, name)
+		_, err = c.QuoteEquity(context.Background(), "MITCON")
+		assert.ErrorIs(t, err, ErrUnauthorized, name)
+		server.Close()
+	}
+}
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"nse/lib/nse"
+)
+
+// dayFormat is the format of the days in files
+const dayFormat = "2006-01-02"
+
+var fileHeader = []string{"date", "open", "high", "low", "close", "prev_close", "volume", "value", "trades", "vwap"}
+
+// FileBackend is the default Backend, storing the candles of each key in a
+// CSV file named <SYMBOL>.<SERIES>.csv in a directory, and its sync state in
+// <SYMBOL>.<SERIES>.sync.json
+type FileBackend struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileBackend creates a FileBackend in dir, creating the directory if needed
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileBackend{dir: dir}, nil
+}
+
+func (f *FileBackend) path(key Key) string {
+	return f.base(key) + ".csv"
+}
+
+func (f *FileBackend) statePath(key Key) string {
+	return f.base(key) + ".sync.json"
+}
+
+func (f *FileBackend) base(key Key) string {
+	key = key.normalize()
+	return filepath.Join(f.dir, url.PathEscape(key.Symbol)+"."+url.PathEscape(key.Series))
+}
+
+// Load implements Backend
+func (f *FileBackend) Load(ctx context.Context, key Key, from, to time.Time) (nse.Candles, error) {
+	f.mu.Lock()
+	candles, err := f.read(key)
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if to.IsZero() {
+		to = time.Date(9999, 12, 31, 0, 0, 0, 0, nse.IST)
+	}
+	return candles.Between(day(from), day(to)), nil
+}
+
+// Save implements Backend. The file is replaced atomically, so readers never
+// see a partial file.
+func (f *FileBackend) Save(ctx context.Context, key Key, candles nse.Candles) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stored, err := f.read(key)
+	if err != nil {
+		return err
+	}
+	return f.write(key, mergeCandles(stored, candles))
+}
+
+// LoadState implements Backend
+func (f *FileBackend) LoadState(ctx context.Context, key Key) (SyncState, error) {
+	f.mu.Lock()
+	data, err := os.ReadFile(f.statePath(key))
+	f.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return SyncState{}, nil
+	}
+	if err != nil {
+		return SyncState{}, err
+	}
+	return decodeState(data)
+}
+
+// SaveState implements Backend, replacing the file atomically
+func (f *FileBackend) SaveState(ctx context.Context, key Key, state SyncState) error {
+	data, err := encodeState(state)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.replace(f.statePath(key), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (f *FileBackend) read(key Key) (nse.Candles, error) {
+	file, err := os.Open(f.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = len(fileHeader)
+	if _, err := r.Read(); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("store: reading %s: %w", file.Name(), err)
+	}
+
+	var candles nse.Candles
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return candles, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("store: reading %s: %w", file.Name(), err)
+		}
+		c, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("store: reading %s: %w", file.Name(), err)
+		}
+		candles = append(candles, c)
+	}
+}
+
+func (f *FileBackend) write(key Key, candles nse.Candles) error {
+	return f.replace(f.path(key), func(out io.Writer) error {
+		w := csv.NewWriter(out)
+		w.Write(fileHeader)
+		for _, c := range candles {
+			w.Write(formatRecord(c))
+		}
+		w.Flush()
+		return w.Error()
+	})
+}
+
+// replace writes the file at path with write through a temporary file, so
+// readers never see a partial file
+func (f *FileBackend) replace(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(f.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func formatRecord(c nse.Candle) []string {
+	float := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		c.Time.In(nse.IST).Format(dayFormat),
+		float(c.Open), float(c.High), float(c.Low), float(c.Close), float(c.PrevClose),
+		strconv.FormatInt(c.Volume, 10), float(c.Value), strconv.FormatInt(c.Trades, 10), float(c.VWAP),
+	}
+}
+
+func parseRecord(record []string) (nse.Candle, error) {
+	var c nse.Candle
+	var err error
+	if c.Time, err = time.ParseInLocation(dayFormat, record[0], nse.IST); err != nil {
+		return c, err
+	}
+	floats := []*float64{&c.Open, &c.High, &c.Low, &c.Close, &c.PrevClose}
+	for i, p := range floats {
+		if *p, err = strconv.ParseFloat(record[1+i], 64); err != nil {
+			return c, err
+		}
+	}
+	if c.Volume, err = strconv.ParseInt(record[6], 10, 64); err != nil {
+		return c, err
+	}
+	if c.Value, err = strconv.ParseFloat(record[7], 64); err != nil {
+		return c, err
+	}
+	if c.Trades, err = strconv.ParseInt(record[8], 10, 64); err != nil {
+		return c, err
+	}
+	c.VWAP, err = strconv.ParseFloat(record[9], 64)
+	return c, err
+}
+
+// day returns midnight IST of the day of t
+func day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.In(nse.IST).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, nse.IST)
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"nse/lib/nse"
+)
+
+// SQLite is a Backend storing candles and sync states in tables of a SQLite
+// database. The database is opened by the caller, with the driver of their
+// choice such as modernc.org/sqlite or github.com/mattn/go-sqlite3, so this
+// package does not depend on one.
+type SQLite struct {
+	db *sql.DB
+}
+
+const createTable = `CREATE TABLE IF NOT EXISTS nse_candles (
+	symbol     TEXT NOT NULL,
+	series     TEXT NOT NULL,
+	day        TEXT NOT NULL,
+	open       REAL NOT NULL,
+	high       REAL NOT NULL,
+	low        REAL NOT NULL,
+	close      REAL NOT NULL,
+	prev_close REAL NOT NULL,
+	volume     INTEGER NOT NULL,
+	value      REAL NOT NULL,
+	trades     INTEGER NOT NULL,
+	vwap       REAL NOT NULL,
+	PRIMARY KEY (symbol, series, day)
+)`
+
+const createStateTable = `CREATE TABLE IF NOT EXISTS nse_sync_state (
+	symbol TEXT NOT NULL,
+	series TEXT NOT NULL,
+	state  TEXT NOT NULL,
+	PRIMARY KEY (symbol, series)
+)`
+
+// NewSQLite creates a SQLite backend in db, creating its nse_candles and
+// nse_sync_state tables if needed
+func NewSQLite(ctx context.Context, db *sql.DB) (*SQLite, error) {
+	for _, create := range []string{createTable, createStateTable} {
+		if _, err := db.ExecContext(ctx, create); err != nil {
+			return nil, fmt.Errorf("store: creating table: %w", err)
+		}
+	}
+	return &SQLite{db: db}, nil
+}
+
+// Load implements Backend
+func (s *SQLite) Load(ctx context.Context, key Key, from, to time.Time) (nse.Candles, error) {
+	key = key.normalize()
+	last := "9999-12-31"
+	if !to.IsZero() {
+		last = to.In(nse.IST).Format(dayFormat)
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT day, open, high, low, close, prev_close, volume, value, trades, vwap
+		FROM nse_candles WHERE symbol = ? AND series = ? AND day >= ? AND day <= ? ORDER BY day`,
+		key.Symbol, key.Series, day(from).Format(dayFormat), last)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles nse.Candles
+	for rows.Next() {
+		var c nse.Candle
+		var date string
+		if err := rows.Scan(&date, &c.Open, &c.High, &c.Low, &c.Close, &c.PrevClose, &c.Volume, &c.Value, &c.Trades, &c.VWAP); err != nil {
+			return nil, err
+		}
+		if c.Time, err = time.ParseInLocation(dayFormat, date, nse.IST); err != nil {
+			return nil, fmt.Errorf("store: invalid day %q: %w", date, err)
+		}
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
+// Save implements Backend, in one transaction
+func (s *SQLite) Save(ctx context.Context, key Key, candles nse.Candles) error {
+	key = key.normalize()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR REPLACE INTO nse_candles
+		(symbol, series, day, open, high, low, close, prev_close, volume, value, trades, vwap)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		if _, err := stmt.ExecContext(ctx, key.Symbol, key.Series, c.Time.In(nse.IST).Format(dayFormat),
+			c.Open, c.High, c.Low, c.Close, c.PrevClose, c.Volume, c.Value, c.Trades, c.VWAP); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadState implements Backend
+func (s *SQLite) LoadState(ctx context.Context, key Key) (SyncState, error) {
+	key = key.normalize()
+	var state string
+	err := s.db.QueryRowContext(ctx, `SELECT state FROM nse_sync_state WHERE symbol = ? AND series = ?`,
+		key.Symbol, key.Series).Scan(&state)
+	if errors.Is(err, sql.ErrNoRows) {
+		return SyncState{}, nil
+	}
+	if err != nil {
+		return SyncState{}, err
+	}
+	return decodeState([]byte(state))
+}
+
+// SaveState implements Backend
+func (s *SQLite) SaveState(ctx context.Context, key Key, state SyncState) error {
+	key = key.normalize()
+	data, err := encodeState(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO nse_sync_state (symbol, series, state) VALUES (?, ?, ?)`,
+		key.Symbol, key.Series, string(data))
+	return err
+}
@@ -0,0 +1,335 @@
+// Package store keeps a local copy of daily equity history, so that only the
+// trading days missing since the last sync are downloaded from NSE and the
+// history can be read offline.
+//
+// Next to the candles, the store records a SyncState per key: the days it
+// has synced, and the ranges whose download failed. Days synced without a
+// candle, such as holidays the calendar does not know or suspensions, are
+// not asked for again; only the recorded failures are.
+//
+// Candles are kept per Key in a Backend: FileBackend, storing a CSV file per
+// symbol and series, or SQLite, storing them in a database opened by the
+// caller.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nse/lib/nse"
+	"nse/lib/nse/calendar"
+)
+
+// Key identifies the history of one symbol in one series, such as EQ or BE
+type Key struct {
+	Symbol string
+	Series string
+}
+
+// normalize upper-cases k and defaults its series to EQ
+func (k Key) normalize() Key {
+	k.Symbol = strings.ToUpper(strings.TrimSpace(k.Symbol))
+	k.Series = strings.ToUpper(strings.TrimSpace(k.Series))
+	if k.Series == "" {
+		k.Series = "EQ"
+	}
+	return k
+}
+
+// Backend persists daily candles. Implementations must be safe for
+// concurrent use.
+type Backend interface {
+	// Load returns the candles of key from the day of from to the day of to,
+	// both included, in date order. A zero to means no upper bound.
+	Load(ctx context.Context, key Key, from, to time.Time) (nse.Candles, error)
+	// Save stores candles, replacing those of the same days
+	Save(ctx context.Context, key Key, candles nse.Candles) error
+	// LoadState returns the sync state of key, the zero SyncState if key
+	// was never synced
+	LoadState(ctx context.Context, key Key) (SyncState, error)
+	// SaveState replaces the sync state of key
+	SaveState(ctx context.Context, key Key, state SyncState) error
+}
+
+// SyncState records which days of a key Sync has covered
+type SyncState struct {
+	// From and Through are the first and last day synced. Days between them
+	// without a candle had no trading, except those in Gaps.
+	From    time.Time
+	Through time.Time
+	// Gaps are the ranges whose download failed; the next Sync fetches them again
+	Gaps []nse.DateRange
+}
+
+// stateJSON is how backends persist a SyncState
+type stateJSON struct {
+	From    string      `json:"from"`
+	Through string      `json:"through"`
+	Gaps    [][2]string `json:"gaps,omitempty"`
+}
+
+func encodeState(state SyncState) ([]byte, error) {
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.In(nse.IST).Format(dayFormat)
+	}
+	s := stateJSON{From: format(state.From), Through: format(state.Through)}
+	for _, g := range state.Gaps {
+		s.Gaps = append(s.Gaps, [2]string{format(g.Start), format(g.End)})
+	}
+	return json.Marshal(s)
+}
+
+func decodeState(data []byte) (SyncState, error) {
+	var s stateJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		return SyncState{}, fmt.Errorf("store: invalid sync state: %w", err)
+	}
+	parse := func(v string) (time.Time, error) {
+		if v == "" {
+			return time.Time{}, nil
+		}
+		t, err := time.ParseInLocation(dayFormat, v, nse.IST)
+		if err != nil {
+			return t, fmt.Errorf("store: invalid sync state: %w", err)
+		}
+		return t, nil
+	}
+	var state SyncState
+	var err error
+	if state.From, err = parse(s.From); err != nil {
+		return state, err
+	}
+	if state.Through, err = parse(s.Through); err != nil {
+		return state, err
+	}
+	for _, g := range s.Gaps {
+		var r nse.DateRange
+		if r.Start, err = parse(g[0]); err != nil {
+			return state, err
+		}
+		if r.End, err = parse(g[1]); err != nil {
+			return state, err
+		}
+		state.Gaps = append(state.Gaps, r)
+	}
+	return state, nil
+}
+
+// Store syncs equity history from NSE into a Backend
+type Store struct {
+	backend  Backend
+	client   *nse.Client
+	calendar *calendar.Calendar
+	now      func() time.Time
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithCalendar sets the trading calendar used to find the missing days. The
+// default is the calendar of the client, see nse.Client.Calendar, or
+// calendar.Default() for a store without a client.
+func WithCalendar(cal *calendar.Calendar) Option {
+	return func(s *Store) {
+		s.calendar = cal
+	}
+}
+
+// New creates a Store keeping its data in backend and downloading it with
+// client. client may be nil for a store that is only read.
+func New(backend Backend, client *nse.Client, opts ...Option) *Store {
+	s := &Store{backend: backend, client: client, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Candles returns the stored candles of symbol in series (EQ when empty)
+// from the day of from to the day of to, both included, without contacting
+// NSE. A zero to means up to the last stored day.
+func (s *Store) Candles(ctx context.Context, symbol, series string, from, to time.Time) (nse.Candles, error) {
+	return s.backend.Load(ctx, Key{symbol, series}.normalize(), from, to)
+}
+
+// SyncResult reports what a Sync downloaded
+type SyncResult struct {
+	Symbol string
+	// Added is the number of days stored, over all series
+	Added int
+	// Gaps are the ranges that could not be fetched; the next Sync tries them again
+	Gaps []nse.DateRange
+}
+
+// ErrOffline is returned by Sync for a store created without a client
+var ErrOffline = errors.New("store: no client to sync with")
+
+// Sync downloads the days of symbol not synced yet, from from (the listing
+// date when zero, for a symbol not synced yet) up to the last trading day
+// whose session has closed. After the first sync, only the days after the
+// last one synced, the days before the first one if from is earlier, and the
+// ranges that failed before are downloaded.
+//
+// If some chunks fail, the others are stored, the failed ranges are recorded
+// for the next Sync, and a *nse.ChunkError is returned with the result.
+func (s *Store) Sync(ctx context.Context, symbol string, from time.Time, opts nse.FetchOptions) (*SyncResult, error) {
+	if s.client == nil {
+		return nil, ErrOffline
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	result := &SyncResult{Symbol: symbol}
+
+	// the primary series keeps the sync state; others are stored as they come
+	key := Key{Symbol: symbol}.normalize()
+	state, err := s.state(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	from = day(from)
+	cal := s.tradingCalendar(ctx)
+	last := lastClosedDay(cal, s.now())
+	ranges := missing(cal, state, from, last)
+	if !state.Through.IsZero() && len(ranges) == 0 {
+		return result, nil
+	}
+
+	download, err := s.client.EquityHistoryChunks(ctx, symbol, ranges, opts)
+	if download == nil {
+		return nil, err
+	}
+	if len(download.Failed) > 0 {
+		result.Gaps = download.Gaps()
+	}
+
+	bySeries := make(map[string][]nse.EquityHistoricalInfo)
+	for _, chunk := range download.Data {
+		for _, row := range chunk.Data {
+			bySeries[row.CHSeries] = append(bySeries[row.CHSeries], row)
+		}
+	}
+	first := from
+	for series, rows := range bySeries {
+		candles := nse.HistoryCandles(rows)
+		if err := s.backend.Save(ctx, Key{symbol, series}.normalize(), candles); err != nil {
+			return nil, err
+		}
+		result.Added += len(candles)
+		if len(candles) > 0 && (first.IsZero() || candles[0].Time.Before(first)) {
+			first = candles[0].Time
+		}
+	}
+
+	if state.From.IsZero() || (!first.IsZero() && first.Before(state.From)) {
+		state.From = first
+	}
+	if state.Through.Before(last) {
+		state.Through = last
+	}
+	state.Gaps = result.Gaps
+	if err := s.backend.SaveState(ctx, key, state); err != nil {
+		return nil, err
+	}
+
+	if err != nil {
+		return result, err
+	}
+	if len(download.Failed) > 0 {
+		return result, &nse.ChunkError{Symbol: symbol, Failed: download.Failed}
+	}
+	return result, nil
+}
+
+// state returns the sync state of key. Stores written before sync states
+// were recorded get one covering their stored candles.
+func (s *Store) state(ctx context.Context, key Key) (SyncState, error) {
+	state, err := s.backend.LoadState(ctx, key)
+	if err != nil || !state.Through.IsZero() {
+		return state, err
+	}
+	stored, err := s.backend.Load(ctx, key, time.Time{}, time.Time{})
+	if err != nil || len(stored) == 0 {
+		return state, err
+	}
+	return SyncState{From: stored[0].Time, Through: stored[len(stored)-1].Time}, nil
+}
+
+// tradingCalendar returns the calendar set with WithCalendar, or else that
+// of the client, so that a store finds the same trading days its client
+// downloads
+func (s *Store) tradingCalendar(ctx context.Context) *calendar.Calendar {
+	switch {
+	case s.calendar != nil:
+		return s.calendar
+	case s.client != nil:
+		return s.client.Calendar(ctx)
+	default:
+		return calendar.Default()
+	}
+}
+
+// lastClosedDay returns the last trading day of cal whose normal session
+// ended before now
+func lastClosedDay(cal *calendar.Calendar, now time.Time) time.Time {
+	if hours, ok := cal.Hours(now); ok && !now.Before(hours.Close) {
+		y, m, d := hours.Close.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, nse.IST)
+	}
+	return cal.PrevTradingDay(now)
+}
+
+// missing returns the ranges to download to sync from from up to last: the
+// failed ranges of state, the days before state.From back to from, and the
+// days after state.Through. Ranges without a trading day of cal are left
+// out. Nil for a key never synced means everything since from, or since the
+// listing date if from is zero.
+func missing(cal *calendar.Calendar, state SyncState, from, last time.Time) []nse.DateRange {
+	if state.Through.IsZero() {
+		if from.IsZero() {
+			return nil
+		}
+		return []nse.DateRange{{Start: from, End: last}}
+	}
+
+	var ranges []nse.DateRange
+	add := func(start, end time.Time) {
+		if len(cal.TradingDaysBetween(start, end)) > 0 {
+			ranges = append(ranges, nse.DateRange{Start: start, End: end})
+		}
+	}
+	if !from.IsZero() && from.Before(state.From) {
+		add(from, state.From.AddDate(0, 0, -1))
+	}
+	for _, g := range state.Gaps {
+		add(g.Start, g.End)
+	}
+	if state.Through.Before(last) {
+		add(state.Through.AddDate(0, 0, 1), last)
+	}
+	return ranges
+}
+
+// mergeCandles merges update into candles, both in date order, with the
+// candles of update replacing those of the same day
+func mergeCandles(candles, update nse.Candles) nse.Candles {
+	byDay := make(map[int64]nse.Candle, len(candles)+len(update))
+	for _, c := range candles {
+		byDay[c.Time.Unix()] = c
+	}
+	for _, c := range update {
+		byDay[c.Time.Unix()] = c
+	}
+	merged := make(nse.Candles, 0, len(byDay))
+	for _, c := range byDay {
+		merged = append(merged, c)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	return merged
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nse/lib/nse"
+	"nse/lib/nse/calendar"
+	"nse/lib/nse/nsetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func march(d int) time.Time {
+	return time.Date(2024, 3, d, 0, 0, 0, 0, nse.IST)
+}
+
+func TestSync(t *testing.T) {
+	server := nsetest.NewServer(t)
+	backend, err := NewFileBackend(t.TempDir())
+	require.NoError(t, err)
+	s := New(backend, server.Client())
+	ctx := context.Background()
+
+	// during the session of the 15th, the 14th is the last complete day
+	s.now = func() time.Time { return march(15).Add(11 * time.Hour) }
+	result, err := s.Sync(ctx, "mitcon", march(11), nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &SyncResult{Symbol: "MITCON", Added: 4}, result)
+
+	s.now = func() time.Time { return march(15).Add(18 * time.Hour) }
+	result, err = s.Sync(ctx, "MITCON", time.Time{}, nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Added)
+	assert.Equal(t, 2, server.Requests("/api/historical/cm/equity"))
+
+	// nothing is missing
+	result, err = s.Sync(ctx, "MITCON", time.Time{}, nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, result.Added)
+	assert.Equal(t, 2, server.Requests("/api/historical/cm/equity"))
+
+	candles, err := New(backend, nil).Candles(ctx, "MITCON", "", march(12), march(14))
+	require.NoError(t, err)
+	require.Len(t, candles, 3)
+	assert.Equal(t, march(12), candles[0].Time)
+	assert.Equal(t, 97.05, candles[0].Close)
+	assert.Equal(t, int64(88410), candles[0].Volume)
+
+	_, err = New(backend, nil).Sync(ctx, "MITCON", time.Time{}, nse.FetchOptions{})
+	assert.ErrorIs(t, err, ErrOffline)
+}
+
+func TestSyncFailure(t *testing.T) {
+	server := nsetest.NewServer(t)
+	backend, err := NewFileBackend(t.TempDir())
+	require.NoError(t, err)
+	s := New(backend, server.Client())
+	s.now = func() time.Time { return march(15).Add(18 * time.Hour) }
+
+	server.Fail("/api/historical/cm/equity", nsetest.Unavailable())
+	result, err := s.Sync(context.Background(), "MITCON", march(11), nse.FetchOptions{})
+	assert.ErrorIs(t, err, nse.ErrUpstreamUnavailable)
+	assert.Zero(t, result.Added)
+	require.Len(t, result.Gaps, 1)
+
+	result, err = s.Sync(context.Background(), "MITCON", march(11), nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Added)
+}
+
+func TestSyncClientCalendar(t *testing.T) {
+	server := nsetest.NewServer(t)
+	server.SetHolidays(map[string]any{"CM": []map[string]string{
+		{"tradingDate": "15-Mar-2024", "description": "Exchange holiday"},
+	}})
+	backend, err := NewFileBackend(t.TempDir())
+	require.NoError(t, err)
+	// without WithCalendar the store uses the holidays its client fetched
+	s := New(backend, server.Client())
+	s.now = func() time.Time { return march(15).Add(18 * time.Hour) }
+	ctx := context.Background()
+
+	result, err := s.Sync(ctx, "MITCON", march(11), nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.Added)
+	assert.Equal(t, 1, server.Requests("/api/holiday-master"))
+
+	state, err := backend.LoadState(ctx, Key{Symbol: "MITCON"})
+	require.NoError(t, err)
+	assert.Equal(t, march(14), state.Through)
+}
+
+func TestSyncUnknownHoliday(t *testing.T) {
+	server := nsetest.NewServer(t)
+	server.SetHistory("MITCON", []nse.EquityHistoricalInfo{
+		{CHSymbol: "MITCON", CHSeries: "EQ", CHTimestamp: nse.Date{Time: march(7)}, CHClosingPrice: 96.5},
+		{CHSymbol: "MITCON", CHSeries: "EQ", CHTimestamp: nse.Date{Time: march(11)}, CHClosingPrice: 97.8},
+	})
+	backend, err := NewFileBackend(t.TempDir())
+	require.NoError(t, err)
+	// a calendar without the holiday of the 8th takes it for a trading day
+	s := New(backend, server.Client(), WithCalendar(calendar.New(nil)))
+	s.now = func() time.Time { return march(11).Add(18 * time.Hour) }
+	ctx := context.Background()
+
+	result, err := s.Sync(ctx, "MITCON", march(7), nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Added)
+	requests := server.Requests("/api/historical/cm/equity")
+
+	// the 8th has no candle but was synced, so it is not asked for again
+	result, err = s.Sync(ctx, "MITCON", march(7), nse.FetchOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, result.Added)
+	assert.Equal(t, requests, server.Requests("/api/historical/cm/equity"))
+
+	state, err := backend.LoadState(ctx, Key{Symbol: "MITCON"})
+	require.NoError(t, err)
+	assert.Equal(t, SyncState{From: march(7), Through: march(11)}, state)
+}
+
+func TestMissing(t *testing.T) {
+	cal := calendar.Default()
+	state := SyncState{From: march(11), Through: march(14), Gaps: []nse.DateRange{{Start: march(12), End: march(12)}}}
+
+	assert.Equal(t, []nse.DateRange{
+		{Start: march(12), End: march(12)},
+		{Start: march(15), End: march(19)},
+	}, missing(cal, state, time.Time{}, march(19)))
+
+	// an earlier start backfills; a range of weekends and holidays is skipped
+	assert.Equal(t, []nse.DateRange{
+		{Start: march(4), End: march(10)},
+		{Start: march(12), End: march(12)},
+	}, missing(cal, state, march(4), march(14)))
+	assert.Equal(t, []nse.DateRange{
+		{Start: march(12), End: march(12)},
+	}, missing(cal, state, march(9), march(14)))
+
+	assert.Nil(t, missing(cal, SyncState{}, time.Time{}, march(19)))
+	assert.Equal(t, []nse.DateRange{{Start: march(11), End: march(19)}}, missing(cal, SyncState{}, march(11), march(19)))
+}
+
+func TestSyncState(t *testing.T) {
+	backend, err := NewFileBackend(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+	key := Key{Symbol: "MITCON"}
+
+	state, err := backend.LoadState(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, state)
+
+	want := SyncState{From: march(1), Through: march(15), Gaps: []nse.DateRange{{Start: march(4), End: march(6)}}}
+	require.NoError(t, backend.SaveState(ctx, key, want))
+	state, err = backend.LoadState(ctx, Key{Symbol: "mitcon", Series: "EQ"})
+	require.NoError(t, err)
+	assert.Equal(t, want, state)
+
+	// a store from before sync states covers its candles
+	require.NoError(t, backend.Save(ctx, Key{Symbol: "OLD"}, nse.Candles{{Time: march(11)}, {Time: march(13)}}))
+	state, err = New(backend, nil).state(ctx, Key{Symbol: "OLD"}.normalize())
+	require.NoError(t, err)
+	assert.Equal(t, SyncState{From: march(11), Through: march(13)}, state)
+}
+
+func TestFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := NewFileBackend(dir)
+	require.NoError(t, err)
+	ctx := context.Background()
+	key := Key{Symbol: "M&M"}
+
+	require.NoError(t, backend.Save(ctx, key, nse.Candles{
+		{Time: march(11), Open: 1, Close: 1.5, Volume: 10},
+		{Time: march(12), Open: 2, Close: 2.5, Volume: 20, VWAP: 2.25},
+	}))
+	require.NoError(t, backend.Save(ctx, key, nse.Candles{
+		{Time: march(12), Open: 2, Close: 2.75, Volume: 25},
+		{Time: march(13), Open: 3, Close: 3.5, Trades: 7},
+	}))
+	_, err = os.Stat(filepath.Join(dir, "M&M.EQ.csv"))
+	require.NoError(t, err)
+
+	candles, err := backend.Load(ctx, Key{Symbol: "m&m", Series: "eq"}, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, nse.Candles{
+		{Time: march(11), Open: 1, Close: 1.5, Volume: 10},
+		{Time: march(12), Open: 2, Close: 2.75, Volume: 25},
+		{Time: march(13), Open: 3, Close: 3.5, Trades: 7},
+	}, candles)
+
+	candles, err = backend.Load(ctx, key, march(12), march(12).Add(time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, candles, 1)
+
+	candles, err = backend.Load(ctx, Key{Symbol: "NOSUCH"}, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, candles)
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	backend, err := NewSQLite(ctx, db)
+	require.NoError(t, err)
+	key := Key{Symbol: "M&M"}
+
+	require.NoError(t, backend.Save(ctx, key, nse.Candles{
+		{Time: march(11), Open: 1, Close: 1.5, Volume: 10},
+		{Time: march(12), Open: 2, Close: 2.5, Volume: 20, VWAP: 2.25},
+	}))
+	require.NoError(t, backend.Save(ctx, key, nse.Candles{
+		{Time: march(12), Open: 2, Close: 2.75, Volume: 25},
+	}))
+	candles, err := backend.Load(ctx, Key{Symbol: "m&m", Series: "eq"}, march(12), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, nse.Candles{{Time: march(12), Open: 2, Close: 2.75, Volume: 25}}, candles)
+
+	state, err := backend.LoadState(ctx, key)
+	require.NoError(t, err)
+	assert.Zero(t, state)
+	want := SyncState{From: march(11), Through: march(12), Gaps: []nse.DateRange{{Start: march(4), End: march(8)}}}
+	require.NoError(t, backend.SaveState(ctx, key, want))
+	state, err = backend.LoadState(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, want, state)
+
+	// creating the tables again keeps the data
+	_, err = NewSQLite(ctx, db)
+	require.NoError(t, err)
+	candles, err = backend.Load(ctx, key, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, candles, 2)
+}
@@ -0,0 +1,76 @@
+package nse
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// istOffsetMillis is the offset of IST from UTC in milliseconds
+const istOffsetMillis = (5*60 + 30) * 60 * 1000
+
+// Tick is one point of an intraday chart
+type Tick struct {
+	Time  time.Time
+	Price float64
+}
+
+// UnmarshalJSON decodes the [epochMillis, price] pairs of NSE charts. NSE
+// sends the IST wall clock time as if it were UTC, and pre-open charts add
+// a third element, which is ignored.
+func (t *Tick) UnmarshalJSON(data []byte) error {
+	var point []any
+	if err := json.Unmarshal(data, &point); err != nil {
+		return err
+	}
+	if len(point) < 2 {
+		return fmt.Errorf("nse: invalid chart point %s", data)
+	}
+	ms, ok1 := point[0].(float64)
+	price, ok2 := point[1].(float64)
+	if !ok1 || !ok2 {
+		return fmt.Errorf("nse: invalid chart point %s", data)
+	}
+	t.Time = time.UnixMilli(int64(ms) - istOffsetMillis).In(IST)
+	t.Price = price
+	return nil
+}
+
+// MarshalJSON encodes t the way NSE does, as [epochMillis, price]
+func (t Tick) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]float64{float64(t.Time.UnixMilli() + istOffsetMillis), t.Price})
+}
+
+// Ticks is an intraday series in ascending time order
+type Ticks []Tick
+
+// Bars resamples the ticks into OHLC bars of interval, such as time.Minute,
+// 5*time.Minute or 15*time.Minute. Bars start at multiples of interval since
+// midnight IST, so 5 and 15 minute bars start at the 09:15 market open.
+// Intervals without ticks have no bar.
+func (ts Ticks) Bars(interval time.Duration) Candles {
+	if interval <= 0 {
+		return nil
+	}
+
+	var bars Candles
+	for _, tick := range ts {
+		t := tick.Time.In(IST)
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, IST)
+		start := midnight.Add(t.Sub(midnight) / interval * interval)
+
+		if n := len(bars); n > 0 && bars[n-1].Time.Equal(start) {
+			bar := &bars[n-1]
+			bar.High = max(bar.High, tick.Price)
+			bar.Low = min(bar.Low, tick.Price)
+			bar.Close = tick.Price
+			continue
+		}
+		bar := Candle{Time: start, Open: tick.Price, High: tick.Price, Low: tick.Price, Close: tick.Price}
+		if n := len(bars); n > 0 {
+			bar.PrevClose = bars[n-1].Close
+		}
+		bars = append(bars, bar)
+	}
+	return bars
+}
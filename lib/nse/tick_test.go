@@ -0,0 +1,45 @@
+package nse
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTickJSON(t *testing.T) {
+	var ticks Ticks
+	require.NoError(t, json.Unmarshal([]byte(`[[1710494100000,99.1],[1710493800000,98.5,"PO"]]`), &ticks))
+	assert.Equal(t, Ticks{
+		{Time: time.Date(2024, 3, 15, 9, 15, 0, 0, IST), Price: 99.1},
+		{Time: time.Date(2024, 3, 15, 9, 10, 0, 0, IST), Price: 98.5},
+	}, ticks)
+
+	data, err := json.Marshal(ticks[0])
+	require.NoError(t, err)
+	assert.Equal(t, `[1710494100000,99.1]`, string(data))
+
+	var tick Tick
+	assert.Error(t, json.Unmarshal([]byte(`[1710494100000]`), &tick))
+	assert.Error(t, json.Unmarshal([]byte(`["09:15",99.1]`), &tick))
+}
+
+func TestTicksBars(t *testing.T) {
+	at := func(h, m, s int) time.Time { return time.Date(2024, 3, 15, h, m, s, 0, IST) }
+	ticks := Ticks{
+		{at(9, 15, 0), 100}, {at(9, 16, 0), 102}, {at(9, 19, 59), 99}, {at(9, 20, 0), 101},
+		{at(9, 31, 0), 103},
+	}
+
+	bars := ticks.Bars(5 * time.Minute)
+	require.Len(t, bars, 3)
+	assert.Equal(t, Candle{Time: at(9, 15, 0), Open: 100, High: 102, Low: 99, Close: 99}, bars[0])
+	assert.Equal(t, Candle{Time: at(9, 20, 0), Open: 101, High: 101, Low: 101, Close: 101, PrevClose: 99}, bars[1])
+	assert.Equal(t, at(9, 30, 0), bars[2].Time)
+
+	assert.Len(t, ticks.Bars(time.Minute), 5)
+	assert.Len(t, ticks.Bars(15*time.Minute), 2)
+	assert.Nil(t, ticks.Bars(0))
+}
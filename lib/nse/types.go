@@ -1,52 +1,51 @@
 package nse
 
 import (
-	"encoding/json"
 	"time"
 )
 
 // Metadata represents metadata information in the JSON
 type Metadata struct {
-	Symbol         string  `json:"symbol"`
-	Identifier     string  `json:"identifier"`
-	Purpose        string  `json:"purpose"`
-	LastPrice      float64 `json:"lastPrice"`
-	Change         float64 `json:"change"`
-	PChange        float64 `json:"pChange"`
-	PreviousClose  float64 `json:"previousClose"`
-	FinalQuantity  int     `json:"finalQuantity"`
-	TotalTurnover  float64 `json:"totalTurnover"`
-	MarketCap      string  `json:"marketCap"`
-	YearHigh       float64 `json:"yearHigh"`
-	YearLow        float64 `json:"yearLow"`
-	Iep            float64 `json:"iep"`
-	ChartTodayPath string  `json:"chartTodayPath"`
+	Symbol         string `json:"symbol"`
+	Identifier     string `json:"identifier"`
+	Purpose        string `json:"purpose"`
+	LastPrice      Float  `json:"lastPrice"`
+	Change         Float  `json:"change"`
+	PChange        Float  `json:"pChange"`
+	PreviousClose  Float  `json:"previousClose"`
+	FinalQuantity  Int    `json:"finalQuantity"`
+	TotalTurnover  Float  `json:"totalTurnover"`
+	MarketCap      Float  `json:"marketCap"`
+	YearHigh       Float  `json:"yearHigh"`
+	YearLow        Float  `json:"yearLow"`
+	Iep            Float  `json:"iep"`
+	ChartTodayPath string `json:"chartTodayPath"`
 }
 
 // Detail represents detailed information in the JSON
 type Detail struct {
 	PreOpenMarket struct {
 		Preopen []struct {
-			Price   float64 `json:"price"`
-			BuyQty  int     `json:"buyQty"`
-			SellQty int     `json:"sellQty"`
+			Price   Float `json:"price"`
+			BuyQty  Int   `json:"buyQty"`
+			SellQty Int   `json:"sellQty"`
 		} `json:"preopen"`
 		Ato struct {
-			TotalBuyQuantity  int `json:"totalBuyQuantity"`
-			TotalSellQuantity int `json:"totalSellQuantity"`
+			TotalBuyQuantity  Int `json:"totalBuyQuantity"`
+			TotalSellQuantity Int `json:"totalSellQuantity"`
 		} `json:"ato"`
-		Iep               float64 `json:"IEP"`
-		TotalTradedVolume int     `json:"totalTradedVolume"`
-		FinalPrice        float64 `json:"finalPrice"`
-		FinalQuantity     int     `json:"finalQuantity"`
-		LastUpdateTime    string  `json:"lastUpdateTime"`
-		TotalSellQuantity int     `json:"totalSellQuantity"`
-		TotalBuyQuantity  int     `json:"totalBuyQuantity"`
-		AtoBuyQty         int     `json:"atoBuyQty"`
-		AtoSellQty        int     `json:"atoSellQty"`
-		Change            float64 `json:"Change"`
-		PerChange         float64 `json:"perChange"`
-		PrevClose         float64 `json:"prevClose"`
+		Iep               Float `json:"IEP"`
+		TotalTradedVolume Int   `json:"totalTradedVolume"`
+		FinalPrice        Float `json:"finalPrice"`
+		FinalQuantity     Int   `json:"finalQuantity"`
+		LastUpdateTime    Date  `json:"lastUpdateTime"`
+		TotalSellQuantity Int   `json:"totalSellQuantity"`
+		TotalBuyQuantity  Int   `json:"totalBuyQuantity"`
+		AtoBuyQty         Int   `json:"atoBuyQty"`
+		AtoSellQty        Int   `json:"atoSellQty"`
+		Change            Float `json:"Change"`
+		PerChange         Float `json:"perChange"`
+		PrevClose         Float `json:"prevClose"`
 	} `json:"preOpenMarket"`
 }
 
@@ -59,10 +58,10 @@ type StockData struct {
 }
 
 type IntradayData struct {
-	Identifier string     `json:"identifier"`
-	Name       string     `json:"name"`
-	GraphData  [2]float64 `json:"graphData"`
-	ClosePrice float64    `json:"closePrice"`
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+	GraphData  Ticks  `json:"grapthData"`
+	ClosePrice Float  `json:"closePrice"`
 }
 
 type DateRange struct {
@@ -90,16 +89,16 @@ type EquityInfo struct {
 }
 
 type EquityMetadata struct {
-	Series         string          `json:"series"`
-	Symbol         string          `json:"symbol"`
-	Isin           string          `json:"isin"`
-	Status         string          `json:"status"`
-	ListingDate    string          `json:"listingDate"`
-	Industry       string          `json:"industry"`
-	LastUpdateTime string          `json:"lastUpdateTime"`
-	PdSectorPe     json.RawMessage `json:"pdSectorPe"`
-	PdSymbolPe     json.RawMessage `json:"pdSymbolPe"`
-	PdSectorInd    string          `json:"pdSectorInd"`
+	Series         string `json:"series"`
+	Symbol         string `json:"symbol"`
+	Isin           string `json:"isin"`
+	Status         string `json:"status"`
+	ListingDate    Date   `json:"listingDate"`
+	Industry       string `json:"industry"`
+	LastUpdateTime Date   `json:"lastUpdateTime"`
+	PdSectorPe     Float  `json:"pdSectorPe"`
+	PdSymbolPe     Float  `json:"pdSymbolPe"`
+	PdSectorInd    string `json:"pdSectorInd"`
 }
 
 type EquitySecurityInfo struct {
@@ -111,9 +110,9 @@ type EquitySecurityInfo struct {
 	ClassOfShare   string       `json:"classOfShare"`
 	Derivatives    string       `json:"derivatives"`
 	Surveillance   Surveillance `json:"surveillance"`
-	FaceValue      float64      `json:"faceValue"`
-	IssuedCap      float64      `json:"issuedCap"`
-	IssuedSize     float64      `json:"issuedSize"`
+	FaceValue      Float        `json:"faceValue"`
+	IssuedCap      Float        `json:"issuedCap"`
+	IssuedSize     Float        `json:"issuedSize"`
 }
 
 type Surveillance struct {
@@ -122,58 +121,58 @@ type Surveillance struct {
 }
 
 type EquityPriceInfo struct {
-	LastPrice       float64 `json:"lastPrice"`
-	Change          float64 `json:"change"`
-	PChange         float64 `json:"pChange"`
-	PreviousClose   float64 `json:"previousClose"`
-	Open            float64 `json:"open"`
-	Close           float64 `json:"close"`
-	Vwap            float64 `json:"vwap"`
-	LowerCP         string  `json:"lowerCP"`
-	UpperCP         string  `json:"upperCP"`
-	PPriceBand      string  `json:"pPriceBand"`
-	BasePrice       float64 `json:"basePrice"`
+	LastPrice       Float `json:"lastPrice"`
+	Change          Float `json:"change"`
+	PChange         Float `json:"pChange"`
+	PreviousClose   Float `json:"previousClose"`
+	Open            Float `json:"open"`
+	Close           Float `json:"close"`
+	Vwap            Float `json:"vwap"`
+	LowerCP         Float `json:"lowerCP"`
+	UpperCP         Float `json:"upperCP"`
+	PPriceBand      Float `json:"pPriceBand"`
+	BasePrice       Float `json:"basePrice"`
 	IntraDayHighLow struct {
-		Min   float64 `json:"min"`
-		Max   float64 `json:"max"`
-		Value float64 `json:"value"`
+		Min   Float `json:"min"`
+		Max   Float `json:"max"`
+		Value Float `json:"value"`
 	} `json:"intraDayHighLow"`
 	WeekHighLow struct {
-		Min     float64 `json:"min"`
-		MinDate string  `json:"minDate"`
-		Max     float64 `json:"max"`
-		MaxDate string  `json:"maxDate"`
-		Value   float64 `json:"value"`
+		Min     Float `json:"min"`
+		MinDate Date  `json:"minDate"`
+		Max     Float `json:"max"`
+		MaxDate Date  `json:"maxDate"`
+		Value   Float `json:"value"`
 	} `json:"weekHighLow"`
-	INavValue string `json:"iNavValue"`
-	CheckINAV bool   `json:"checkINAV"`
+	INavValue Float `json:"iNavValue"`
+	CheckINAV bool  `json:"checkINAV"`
 }
 
 type PreOpenDetails struct {
-	Price   float64 `json:"price"`
-	BuyQty  int     `json:"buyQty"`
-	SellQty int     `json:"sellQty"`
-	Iep     bool    `json:"iep"`
+	Price   Float `json:"price"`
+	BuyQty  Int   `json:"buyQty"`
+	SellQty Int   `json:"sellQty"`
+	Iep     bool  `json:"iep"`
 }
 
 type EquityPreOpenMarket struct {
 	Preopen []PreOpenDetails `json:"preopen"`
 	Ato     struct {
-		Buy  int `json:"buy"`
-		Sell int `json:"sell"`
+		Buy  Int `json:"buy"`
+		Sell Int `json:"sell"`
 	} `json:"ato"`
-	IEP               float64 `json:"IEP"`
-	TotalTradedVolume int     `json:"totalTradedVolume"`
-	FinalPrice        float64 `json:"finalPrice"`
-	FinalQuantity     int     `json:"finalQuantity"`
-	LastUpdateTime    string  `json:"lastUpdateTime"`
-	TotalBuyQuantity  int     `json:"totalBuyQuantity"`
-	TotalSellQuantity int     `json:"totalSellQuantity"`
-	AtoBuyQty         int     `json:"atoBuyQty"`
-	AtoSellQty        int     `json:"atoSellQty"`
-	Change            float64 `json:"Change"`
-	PerChange         float64 `json:"perChange"`
-	PrevClose         float64 `json:"prevClose"`
+	IEP               Float `json:"IEP"`
+	TotalTradedVolume Int   `json:"totalTradedVolume"`
+	FinalPrice        Float `json:"finalPrice"`
+	FinalQuantity     Int   `json:"finalQuantity"`
+	LastUpdateTime    Date  `json:"lastUpdateTime"`
+	TotalBuyQuantity  Int   `json:"totalBuyQuantity"`
+	TotalSellQuantity Int   `json:"totalSellQuantity"`
+	AtoBuyQty         Int   `json:"atoBuyQty"`
+	AtoSellQty        Int   `json:"atoSellQty"`
+	Change            Float `json:"Change"`
+	PerChange         Float `json:"perChange"`
+	PrevClose         Float `json:"prevClose"`
 }
 
 type EquityDetails struct {
@@ -204,38 +203,38 @@ type EquityTradeInfo struct {
 		Name string `json:"name"`
 	} `json:"bulkBlockDeals"`
 	MarketDeptOrderBook struct {
-		TotalBuyQuantity  int `json:"totalBuyQuantity"`
-		TotalSellQuantity int `json:"totalSellQuantity"`
+		TotalBuyQuantity  Int `json:"totalBuyQuantity"`
+		TotalSellQuantity Int `json:"totalSellQuantity"`
 		Bid               []struct {
-			Price    float64 `json:"price"`
-			Quantity int     `json:"quantity"`
+			Price    Float `json:"price"`
+			Quantity Int   `json:"quantity"`
 		} `json:"bid"`
 		Ask []struct {
-			Price    float64 `json:"price"`
-			Quantity int     `json:"quantity"`
+			Price    Float `json:"price"`
+			Quantity Int   `json:"quantity"`
 		} `json:"ask"`
 		TradeInfo struct {
-			TotalTradedVolume int     `json:"totalTradedVolume"`
-			TotalTradedValue  float64 `json:"totalTradedValue"`
-			TotalMarketCap    float64 `json:"totalMarketCap"`
-			Ffmc              float64 `json:"ffmc"`
-			ImpactCost        float64 `json:"impactCost"`
+			TotalTradedVolume Int   `json:"totalTradedVolume"`
+			TotalTradedValue  Float `json:"totalTradedValue"`
+			TotalMarketCap    Float `json:"totalMarketCap"`
+			Ffmc              Float `json:"ffmc"`
+			ImpactCost        Float `json:"impactCost"`
 		} `json:"tradeInfo"`
 		ValueAtRisk struct {
-			SecurityVar       float64 `json:"securityVar"`
-			IndexVar          float64 `json:"indexVar"`
-			VarMargin         float64 `json:"varMargin"`
-			ExtremeLossMargin float64 `json:"extremeLossMargin"`
-			AdhocMargin       float64 `json:"adhocMargin"`
-			ApplicableMargin  float64 `json:"applicableMargin"`
+			SecurityVar       Float `json:"securityVar"`
+			IndexVar          Float `json:"indexVar"`
+			VarMargin         Float `json:"varMargin"`
+			ExtremeLossMargin Float `json:"extremeLossMargin"`
+			AdhocMargin       Float `json:"adhocMargin"`
+			ApplicableMargin  Float `json:"applicableMargin"`
 		} `json:"valueAtRisk"`
 	} `json:"marketDeptOrderBook"`
 	SecurityWiseDP struct {
-		QuantityTraded           int    `json:"quantityTraded"`
-		DeliveryQuantity         int    `json:"deliveryQuantity"`
-		DeliveryToTradedQuantity int    `json:"deliveryToTradedQuantity"`
+		QuantityTraded           Int    `json:"quantityTraded"`
+		DeliveryQuantity         Int    `json:"deliveryQuantity"`
+		DeliveryToTradedQuantity Int    `json:"deliveryToTradedQuantity"`
 		SeriesRemarks            string `json:"seriesRemarks"`
-		SecWiseDelPosDate        string `json:"secWiseDelPosDate"`
+		SecWiseDelPosDate        Date   `json:"secWiseDelPosDate"`
 	} `json:"securityWiseDP"`
 }
 
@@ -252,34 +251,45 @@ type DirectoryDetails struct {
 	Email      string `json:"email"`
 }
 
+// Announcement is a corporate announcement filed with the exchange
+type Announcement struct {
+	Desc         string `json:"desc"`
+	AttchmntText string `json:"attchmntText"`
+	AttchmntFile string `json:"attchmntFile"`
+	AnDt         Date   `json:"an_dt"`
+}
+
+// BoardMeeting is a board meeting intimated to the exchange. BmTimestamp is
+// when it was intimated.
+type BoardMeeting struct {
+	BmPurpose   string `json:"bm_purpose"`
+	BmDesc      string `json:"bm_desc"`
+	Attachment  string `json:"attachment"`
+	BmDate      Date   `json:"bm_date"`
+	BmTimestamp Date   `json:"bm_timestamp"`
+}
+
+// CorporateAction is a record of the corporate actions of a security. Subject
+// describes the action, e.g. "Bonus 1:1"; see Events.
+type CorporateAction struct {
+	Series      string `json:"series"`
+	FaceVal     Float  `json:"faceVal"`
+	Subject     string `json:"subject"`
+	ExDate      Date   `json:"exDate"`
+	RecDate     Date   `json:"recDate"`
+	BcStartDate Date   `json:"bcStartDate"`
+	BcEndDate   Date   `json:"bcEndDate"`
+	NdStartDate Date   `json:"ndStartDate"`
+	NdEndDate   Date   `json:"ndEndDate"`
+}
+
 type EquityCorporateInfo struct {
 	Corporate struct {
-		Announcements []struct {
-			Desc         string `json:"desc"`
-			AttchmntText string `json:"attchmntText"`
-			AttchmntFile string `json:"attchmntFile"`
-			AnDt         string `json:"an_dt"`
-		} `json:"announcements"`
-		BoardMeetings []struct {
-			BmPurpose   string `json:"bm_purpose"`
-			BmDesc      string `json:"bm_desc"`
-			Attachment  string `json:"attachment"`
-			BmDate      string `json:"bm_date"`
-			BmTimestamp string `json:"bm_timestamp"`
-		} `json:"boardMeetings"`
-		CorporateActions []struct {
-			Series      string `json:"series"`
-			FaceVal     string `json:"faceVal"`
-			Subject     string `json:"subject"`
-			ExDate      string `json:"exDate"`
-			RecDate     string `json:"recDate"`
-			BcStartDate string `json:"bcStartDate"`
-			BcEndDate   string `json:"bcEndDate"`
-			NdStartDate string `json:"ndStartDate"`
-			NdEndDate   string `json:"ndEndDate"`
-		} `json:"corporateActions"`
-		Governance           []interface{} `json:"governance"`
-		FinancialResults     []interface{} `json:"financialResults"`
+		Announcements        Announcements    `json:"announcements"`
+		BoardMeetings        BoardMeetings    `json:"boardMeetings"`
+		CorporateActions     CorporateActions `json:"corporateActions"`
+		Governance           []interface{}    `json:"governance"`
+		FinancialResults     []interface{}    `json:"financialResults"`
 		ShareholdingPatterns struct {
 			Cols []interface{} `json:"cols"`
 			Data []interface{} `json:"data"`
@@ -305,37 +315,37 @@ type EquityCorporateInfo struct {
 }
 
 type EquityHistoricalInfo struct {
-	ID               string  `json:"_id"`
-	CHSymbol         string  `json:"CH_SYMBOL"`
-	CHSeries         string  `json:"CH_SERIES"`
-	CHMarketType     string  `json:"CH_MARKET_TYPE"`
-	CHTradeHighPrice float64 `json:"CH_TRADE_HIGH_PRICE"`
-	CHTradeLowPrice  float64 `json:"CH_TRADE_LOW_PRICE"`
-	CHOpeningPrice   float64 `json:"CH_OPENING_PRICE"`
-	CHClosingPrice   float64 `json:"CH_CLOSING_PRICE"`
+	ID               string `json:"_id"`
+	CHSymbol         string `json:"CH_SYMBOL"`
+	CHSeries         string `json:"CH_SERIES"`
+	CHMarketType     string `json:"CH_MARKET_TYPE"`
+	CHTradeHighPrice Float  `json:"CH_TRADE_HIGH_PRICE"`
+	CHTradeLowPrice  Float  `json:"CH_TRADE_LOW_PRICE"`
+	CHOpeningPrice   Float  `json:"CH_OPENING_PRICE"`
+	CHClosingPrice   Float  `json:"CH_CLOSING_PRICE"`
 
-	CHLastTradedPrice  float64 `json:"CH_LAST_TRADED_PRICE"`
-	CHPreviousClsPrice float64 `json:"CH_PREVIOUS_CLS_PRICE"`
-	CHTotTradedQty     float64 `json:"CH_TOT_TRADED_QTY"`
-	CHTotTradedVal     float64 `json:"CH_TOT_TRADED_VAL"`
-	CH52WeekHighPrice  float64 `json:"CH_52WEEK_HIGH_PRICE"`
-	CH52WeekLowPrice   float64 `json:"CH_52WEEK_LOW_PRICE"`
-	CHTotalTrades      float64 `json:"CH_TOTAL_TRADES"`
-	CHISIN             string  `json:"CH_ISIN"`
-	CHTimestamp        string  `json:"CH_TIMESTAMP"`
-	Timestamp          string  `json:"TIMESTAMP"`
-	CreatedAt          string  `json:"createdAt"`
-	UpdatedAt          string  `json:"updatedAt"`
-	VWAP               float64 `json:"VWAP"`
-	MTimestamp         string  `json:"mTIMESTAMP"`
+	CHLastTradedPrice  Float  `json:"CH_LAST_TRADED_PRICE"`
+	CHPreviousClsPrice Float  `json:"CH_PREVIOUS_CLS_PRICE"`
+	CHTotTradedQty     Float  `json:"CH_TOT_TRADED_QTY"`
+	CHTotTradedVal     Float  `json:"CH_TOT_TRADED_VAL"`
+	CH52WeekHighPrice  Float  `json:"CH_52WEEK_HIGH_PRICE"`
+	CH52WeekLowPrice   Float  `json:"CH_52WEEK_LOW_PRICE"`
+	CHTotalTrades      Float  `json:"CH_TOTAL_TRADES"`
+	CHISIN             string `json:"CH_ISIN"`
+	CHTimestamp        Date   `json:"CH_TIMESTAMP"`
+	Timestamp          string `json:"TIMESTAMP"`
+	CreatedAt          string `json:"createdAt"`
+	UpdatedAt          string `json:"updatedAt"`
+	VWAP               Float  `json:"VWAP"`
+	MTimestamp         Date   `json:"mTIMESTAMP"`
 }
 
 type EquityHistoricalData struct {
 	Data []EquityHistoricalInfo `json:"data"`
 	Meta struct {
 		Series   []string `json:"series"`
-		FromDate string   `json:"fromDate"`
-		ToDate   string   `json:"toDate"`
+		FromDate Date     `json:"fromDate"`
+		ToDate   Date     `json:"toDate"`
 		Symbols  []string `json:"symbols"`
 	} `json:"meta"`
 }
@@ -343,20 +353,20 @@ type EquityHistoricalData struct {
 type IndexHistoricalData struct {
 	Data struct {
 		IndexCloseOnlineRecords []struct {
-			EODCloseIndexVal float64 `json:"EOD_CLOSE_INDEX_VAL"`
-			EODHighIndexVal  float64 `json:"EOD_HIGH_INDEX_VAL"`
-			EODIndexName     string  `json:"EOD_INDEX_NAME"`
-			EODLowIndexVal   float64 `json:"EOD_LOW_INDEX_VAL"`
-			EODOpenIndexVal  float64 `json:"EOD_OPEN_INDEX_VAL"`
-			EODTimestamp     string  `json:"EOD_TIMESTAMP"`
-			TIMESTAMP        string  `json:"TIMESTAMP"`
+			EODCloseIndexVal Float  `json:"EOD_CLOSE_INDEX_VAL"`
+			EODHighIndexVal  Float  `json:"EOD_HIGH_INDEX_VAL"`
+			EODIndexName     string `json:"EOD_INDEX_NAME"`
+			EODLowIndexVal   Float  `json:"EOD_LOW_INDEX_VAL"`
+			EODOpenIndexVal  Float  `json:"EOD_OPEN_INDEX_VAL"`
+			EODTimestamp     Date   `json:"EOD_TIMESTAMP"`
+			TIMESTAMP        string `json:"TIMESTAMP"`
 		} `json:"indexCloseOnlineRecords"`
 		IndexTurnoverRecords []struct {
-			HITIndexNameUpper string  `json:"HIT_INDEX_NAME_UPPER"`
-			HITTimestamp      string  `json:"HIT_TIMESTAMP"`
-			HITTradedQty      float64 `json:"HIT_TRADED_QTY"`
-			HITTurnOver       float64 `json:"HIT_TURN_OVER"`
-			TIMESTAMP         string  `json:"TIMESTAMP"`
+			HITIndexNameUpper string `json:"HIT_INDEX_NAME_UPPER"`
+			HITTimestamp      Date   `json:"HIT_TIMESTAMP"`
+			HITTradedQty      Float  `json:"HIT_TRADED_QTY"`
+			HITTurnOver       Float  `json:"HIT_TURN_OVER"`
+			TIMESTAMP         string `json:"TIMESTAMP"`
 		} `json:"indexTurnoverRecords"`
 	} `json:"data"`
 }
@@ -366,32 +376,32 @@ type SeriesData struct {
 }
 
 type IndexEquityInfo struct {
-	Priority          int     `json:"priority"`
-	Symbol            string  `json:"symbol"`
-	Identifier        string  `json:"identifier"`
-	Series            string  `json:"series"`
-	Open              float64 `json:"open"`
-	DayHigh           float64 `json:"dayHigh"`
-	DayLow            float64 `json:"dayLow"`
-	LastPrice         float64 `json:"lastPrice"`
-	PreviousClose     float64 `json:"previousClose"`
-	Change            float64 `json:"change"`
-	PChange           float64 `json:"pChange"`
-	TotalTradedVolume float64 `json:"totalTradedVolume"`
-	TotalTradedValue  float64 `json:"totalTradedValue"`
-	LastUpdateTime    string  `json:"lastUpdateTime"`
-	YearHigh          float64 `json:"yearHigh"`
-	FFMC              float64 `json:"ffmc"`
-	YearLow           float64 `json:"yearLow"`
-	NearWKH           float64 `json:"nearWKH"`
-	NearWKL           float64 `json:"nearWKL"`
-	PerChange365d     float64 `json:"perChange365d"`
-	Date365dAgo       string  `json:"date365dAgo"`
-	Chart365dPath     string  `json:"chart365dPath"`
-	Date30dAgo        string  `json:"date30dAgo"`
-	PerChange30d      float64 `json:"perChange30d"`
-	Chart30dPath      string  `json:"chart30dPath"`
-	ChartTodayPath    string  `json:"chartTodayPath"`
+	Priority          Int    `json:"priority"`
+	Symbol            string `json:"symbol"`
+	Identifier        string `json:"identifier"`
+	Series            string `json:"series"`
+	Open              Float  `json:"open"`
+	DayHigh           Float  `json:"dayHigh"`
+	DayLow            Float  `json:"dayLow"`
+	LastPrice         Float  `json:"lastPrice"`
+	PreviousClose     Float  `json:"previousClose"`
+	Change            Float  `json:"change"`
+	PChange           Float  `json:"pChange"`
+	TotalTradedVolume Float  `json:"totalTradedVolume"`
+	TotalTradedValue  Float  `json:"totalTradedValue"`
+	LastUpdateTime    Date   `json:"lastUpdateTime"`
+	YearHigh          Float  `json:"yearHigh"`
+	FFMC              Float  `json:"ffmc"`
+	YearLow           Float  `json:"yearLow"`
+	NearWKH           Float  `json:"nearWKH"`
+	NearWKL           Float  `json:"nearWKL"`
+	PerChange365d     Float  `json:"perChange365d"`
+	Date365dAgo       Date   `json:"date365dAgo"`
+	Chart365dPath     string `json:"chart365dPath"`
+	Date30dAgo        Date   `json:"date30dAgo"`
+	PerChange30d      Float  `json:"perChange30d"`
+	Chart30dPath      string `json:"chart30dPath"`
+	ChartTodayPath    string `json:"chartTodayPath"`
 	Meta              struct {
 		Symbol              string        `json:"symbol"`
 		CompanyName         string        `json:"companyName"`
@@ -413,38 +423,93 @@ type IndexEquityInfo struct {
 type IndexDetails struct {
 	Name    string `json:"name"`
 	Advance struct {
-		Declines  string `json:"declines"`
-		Advances  string `json:"advances"`
-		Unchanged string `json:"unchanged"`
+		Declines  Int `json:"declines"`
+		Advances  Int `json:"advances"`
+		Unchanged Int `json:"unchanged"`
 	} `json:"advance"`
-	Timestamp string            `json:"timestamp"`
+	Timestamp Date              `json:"timestamp"`
 	Data      []IndexEquityInfo `json:"data"`
 	Metadata  struct {
-		IndexName         string  `json:"indexName"`
-		Open              float64 `json:"open"`
-		High              float64 `json:"high"`
-		Low               float64 `json:"low"`
-		PreviousClose     float64 `json:"previousClose"`
-		Last              float64 `json:"last"`
-		PercChange        float64 `json:"percChange"`
-		Change            float64 `json:"change"`
-		TimeVal           string  `json:"timeVal"`
-		YearHigh          float64 `json:"yearHigh"`
-		YearLow           float64 `json:"yearLow"`
-		TotalTradedVolume float64 `json:"totalTradedVolume"`
-		TotalTradedValue  float64 `json:"totalTradedValue"`
-		FfmcSum           float64 `json:"ffmc_sum"`
+		IndexName         string `json:"indexName"`
+		Open              Float  `json:"open"`
+		High              Float  `json:"high"`
+		Low               Float  `json:"low"`
+		PreviousClose     Float  `json:"previousClose"`
+		Last              Float  `json:"last"`
+		PercChange        Float  `json:"percChange"`
+		Change            Float  `json:"change"`
+		TimeVal           Date   `json:"timeVal"`
+		YearHigh          Float  `json:"yearHigh"`
+		YearLow           Float  `json:"yearLow"`
+		TotalTradedVolume Float  `json:"totalTradedVolume"`
+		TotalTradedValue  Float  `json:"totalTradedValue"`
+		FfmcSum           Float  `json:"ffmc_sum"`
 	} `json:"metadata"`
 	MarketStatus struct {
-		Market              string  `json:"market"`
-		MarketStatus        string  `json:"marketStatus"`
-		TradeDate           string  `json:"tradeDate"`
-		Index               string  `json:"index"`
-		Last                float64 `json:"last"`
-		Variation           float64 `json:"variation"`
-		PercentChange       float64 `json:"percentChange"`
-		MarketStatusMessage string  `json:"marketStatusMessage"`
+		Market              string `json:"market"`
+		MarketStatus        string `json:"marketStatus"`
+		TradeDate           Date   `json:"tradeDate"`
+		Index               string `json:"index"`
+		Last                Float  `json:"last"`
+		Variation           Float  `json:"variation"`
+		PercentChange       Float  `json:"percentChange"`
+		MarketStatusMessage string `json:"marketStatusMessage"`
 	} `json:"marketStatus"`
-	Date30dAgo  string `json:"date30dAgo"`
-	Date365dAgo string `json:"date365dAgo"`
+	Date30dAgo  Date `json:"date30dAgo"`
+	Date365dAgo Date `json:"date365dAgo"`
+}
+
+// IndexQuote is the live value of an index in AllIndicesData. Index is the
+// name IndexSnapshot and IndexHistory take.
+type IndexQuote struct {
+	// Key is the category of the index, e.g. "SECTORAL INDICES"
+	Key             string `json:"key"`
+	Index           string `json:"index"`
+	IndexSymbol     string `json:"indexSymbol"`
+	Last            Float  `json:"last"`
+	Variation       Float  `json:"variation"`
+	PercentChange   Float  `json:"percentChange"`
+	Open            Float  `json:"open"`
+	High            Float  `json:"high"`
+	Low             Float  `json:"low"`
+	PreviousClose   Float  `json:"previousClose"`
+	YearHigh        Float  `json:"yearHigh"`
+	YearLow         Float  `json:"yearLow"`
+	IndicativeClose Float  `json:"indicativeClose"`
+	PE              Float  `json:"pe"`
+	PB              Float  `json:"pb"`
+	DY              Float  `json:"dy"`
+	Declines        Int    `json:"declines"`
+	Advances        Int    `json:"advances"`
+	Unchanged       Int    `json:"unchanged"`
+	PerChange365d   Float  `json:"perChange365d"`
+	Date365dAgo     Date   `json:"date365dAgo"`
+	Chart365dPath   string `json:"chart365dPath"`
+	Date30dAgo      Date   `json:"date30dAgo"`
+	PerChange30d    Float  `json:"perChange30d"`
+	Chart30dPath    string `json:"chart30dPath"`
+	ChartTodayPath  string `json:"chartTodayPath"`
+	PreviousDay     Float  `json:"previousDay"`
+	OneWeekAgo      Float  `json:"oneWeekAgo"`
+	OneMonthAgo     Float  `json:"oneMonthAgo"`
+	OneYearAgo      Float  `json:"oneYearAgo"`
+}
+
+// AllIndicesData is the response of /api/allIndices
+type AllIndicesData struct {
+	Data      []IndexQuote `json:"data"`
+	Timestamp Date         `json:"timestamp"`
+	Advances  Int          `json:"advances"`
+	Declines  Int          `json:"declines"`
+	Unchanged Int          `json:"unchanged"`
+	// Dates are the days of the PreviousDay, OneWeekAgo, OneMonthAgo and
+	// OneYearAgo values of the quotes
+	Dates struct {
+		PreviousDay Date `json:"previousDay"`
+		OneWeekAgo  Date `json:"oneWeekAgo"`
+		OneMonthAgo Date `json:"oneMonthAgo"`
+		OneYearAgo  Date `json:"oneYearAgo"`
+	} `json:"dates"`
+	Date30dAgo  Date `json:"date30dAgo"`
+	Date365dAgo Date `json:"date365dAgo"`
 }
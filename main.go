@@ -1,31 +1,94 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 	"nse/lib/nse"
+	"nse/lib/nse/store"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
 )
 
 const (
-	rootCmdUse            = "NSE"
-	rootCmdShort          = "MyApp is a sample command-line application"
-	symbolCmdUse          = "symbol"
-	symbolCmdShort        = "Get Symbols"
-	helpCmdUse            = "help"
-	helpCmdShort          = "Greet someone"
-	quoteEquityCmdUse     = "quote-equity"
-	quoteEquityCmdShort   = "Get Quote Equity"
-	symbolFlagName        = "symbol"
-	symbolFlagShort       = "s"
-	symbolFlagDefault     = "Guest"
-	symbolFlagDescription = "Specify the symbol"
+	rootCmdUse               = "NSE"
+	rootCmdShort             = "MyApp is a sample command-line application"
+	symbolCmdUse             = "symbol"
+	symbolCmdShort           = "Get Symbols"
+	helpCmdUse               = "help"
+	helpCmdShort             = "Greet someone"
+	quoteEquityCmdUse        = "quote-equity"
+	quoteEquityCmdShort      = "Get Quote Equity"
+	symbolFlagName           = "symbol"
+	symbolFlagShort          = "s"
+	symbolFlagDefault        = "Guest"
+	symbolFlagDescription    = "Specify the symbol"
+	verboseFlagName          = "verbose"
+	verboseFlagShort         = "v"
+	verboseFlagDescription   = "Log every NSE request to stderr"
+	historyCmdUse            = "history"
+	historyCmdShort          = "Get daily history of a symbol"
+	fromFlagName             = "from"
+	fromFlagDescription      = "First day, as yyyy-mm-dd (default: listing date)"
+	indexFromFlagDescription = "First day, as yyyy-mm-dd (default: a year ago)"
+	toFlagName               = "to"
+	toFlagDescription        = "Last day, as yyyy-mm-dd (default: today)"
+	parallelFlagName         = "parallel"
+	parallelFlagDefault      = 4
+	parallelFlagDescription  = "Number of chunks downloaded at once"
+	retriesFlagName          = "retries"
+	retriesFlagDefault       = 2
+	retriesFlagDescription   = "Number of times a failed chunk is tried again"
+	dateFlagFormat           = "2006-01-02"
+	indexHistoryCmdUse       = "index-history"
+	indexHistoryCmdShort     = "Get daily history of an index"
+	indexNameFlagName        = "name"
+	indexNameFlagShort       = "n"
+	indexNameFlagDefault     = "NIFTY 50"
+	indexNameFlagDescription = "Specify the index, e.g. \"NIFTY BANK\""
+	syncCmdUse               = "sync"
+	syncCmdShort             = "Download missing daily history into a local store"
+	symbolsFlagName          = "symbols"
+	symbolsFlagDescription   = "Symbols to sync, comma separated"
+	dirFlagName              = "dir"
+	dirFlagDescription       = "Directory of the local store (default: <user cache dir>/nse/history)"
+	syncFromFlagDescription  = "First day for symbols not stored yet, as yyyy-mm-dd (default: listing date)"
+	backendFlagName          = "backend"
+	backendFlagDefault       = "file"
+	backendFlagDescription   = "Store backend: file (a CSV file per symbol) or sqlite (history.db in --dir)"
+	sqliteFileName           = "history.db"
+	intervalFlagName         = "interval"
+	intervalFlagShort        = "i"
+	intervalFlagDefault      = "day"
+	intervalFlagDescription  = "Bar interval: day, week, month, quarter, year or fiscal-year"
 )
 
+// client is configured from the persistent flags before any command runs
+var client *nse.Client
+
 var rootCmd = &cobra.Command{
 	Use:   rootCmdUse,
 	Short: rootCmdShort,
+	// errors are reported by main
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		var opts []nse.Option
+		if verbose, _ := cmd.Flags().GetBool(verboseFlagName); verbose {
+			handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})
+			opts = append(opts, nse.WithLogger(slog.New(handler)))
+		}
+		client = nse.NewClient(opts...)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Use 'help' to know the use")
 	},
@@ -34,11 +97,15 @@ var rootCmd = &cobra.Command{
 var symbolCmd = &cobra.Command{
 	Use:   symbolCmdUse,
 	Short: symbolCmdShort,
-	Run: func(cmd *cobra.Command, args []string) {
-		data := nse.GetSymbols()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := client.GetSymbols(cmd.Context())
+		if err != nil {
+			return err
+		}
 		for _, v := range data {
 			fmt.Println(v)
 		}
+		return nil
 	},
 }
 
@@ -49,22 +116,55 @@ var helpCmd = &cobra.Command{
 		fmt.Println(`Usage:
   nse symbol          Get all symbols
   nse quote-equity    Get Quote Equity for a symbol
+  nse history         Get daily history of a symbol
+  nse index-history   Get daily history of an index
+  nse index           Get the live snapshot of an index and its constituents
+  nse indices         List the live values of all indices by category
+  nse sync            Download missing daily history into a local store
+  nse indicators      Compute technical indicators over the history of a symbol
+  nse announcements   Get the corporate announcements of a symbol
+  nse board-meetings  Get the board meetings of a symbol
+  nse corp-actions    Get the corporate actions of a symbol
+  nse company-info    Get the directory, registrar and annual reports of a symbol
 
 Flags:
   -s, --symbol string    Specify the symbol
+  -n, --name string      Specify the index
+  -c, --category string  Only list indices of categories containing this, e.g. sectoral
+      --symbols strings  Symbols to sync, comma separated
+      --dir string       Directory of the local store
+      --backend string   Store backend: file or sqlite
+  -v, --verbose          Log every NSE request to stderr
+      --from string      First day of history, as yyyy-mm-dd
+      --to string        Last day of history, as yyyy-mm-dd
+      --parallel int     Number of history chunks downloaded at once
+      --retries int      Number of times a failed history chunk is tried again
+  -i, --interval string  Bar interval of history: day, week, month, quarter, year or fiscal-year
 
 Examples:
   nse symbol
-  nse quote-equity --symbol TATATECH`)
+  nse quote-equity --symbol TATATECH
+  nse history --symbol TATATECH --from 2024-01-01 --to 2024-03-31
+  nse history --symbol TATATECH --interval week
+  nse index-history --name "NIFTY BANK" --from 2024-01-01
+  nse sync --symbols TATATECH,MITCON
+  nse sync --symbols TATATECH --backend sqlite
+  nse indicators --symbol TATATECH --rsi 14 --ema 20 --macd
+  nse corp-actions --symbol MITCON --oldest-first
+  nse index --name "NIFTY BANK" --sort 365d
+  nse indices --category sectoral`)
 	},
 }
 
 var quoteEquityCmd = &cobra.Command{
 	Use:   quoteEquityCmdUse,
 	Short: quoteEquityCmdShort,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		symbol, _ := cmd.Flags().GetString(symbolFlagName)
-		data, _ := nse.QuoteEquity(symbol)
+		data, err := client.QuoteEquity(cmd.Context(), symbol)
+		if err != nil {
+			return err
+		}
 		fmt.Printf("Company: %s (%s)\n", data.Info.CompanyName, data.Info.Symbol)
 		fmt.Printf("Industry: %s\n", data.Info.Industry)
 		fmt.Printf("Listing Date: %s\n", data.Metadata.ListingDate)
@@ -77,18 +177,266 @@ var quoteEquityCmd = &cobra.Command{
 		fmt.Printf("Issued Size: %.2f\n", data.SecurityInfo.IssuedSize)
 		fmt.Printf("Week High: ₹%.2f\n", data.PriceInfo.WeekHighLow.Max)
 		fmt.Printf("Week Low: ₹%.2f\n", data.PriceInfo.WeekHighLow.Min)
+		return nil
+	},
+}
+
+var historyCmd = &cobra.Command{
+	Use:   historyCmdUse,
+	Short: historyCmdShort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		symbol, _ := cmd.Flags().GetString(symbolFlagName)
+		candles, failed, err := historyFlags(cmd, symbol)
+		if err != nil {
+			return err
+		}
+		printCandles(candles)
+		return gapsError(symbol, failed)
+	},
+}
+
+var indexHistoryCmd = &cobra.Command{
+	Use:   indexHistoryCmdUse,
+	Short: indexHistoryCmdShort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString(indexNameFlagName)
+		ranges, err := dateRangeFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if ranges == nil {
+			now := time.Now().In(nse.IST)
+			ranges = []nse.DateRange{{Start: now.AddDate(-1, 0, 0), End: now}}
+		}
+
+		opts, bar := fetchOptionsFlags(cmd)
+		result, err := client.IndexHistoryChunks(cmd.Context(), name, ranges, opts)
+		if bar != nil && result != nil {
+			bar.finish()
+		}
+		if err != nil {
+			return err
+		}
+
+		printCandles(nse.IndexHistoryCandles(result.Data))
+		return gapsError(name, result.Failed)
+	},
+}
+
+var syncCmd = &cobra.Command{
+	Use:   syncCmdUse,
+	Short: syncCmdShort,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		symbols, _ := cmd.Flags().GetStringSlice(symbolsFlagName)
+		if len(symbols) == 0 {
+			return fmt.Errorf("--%s is required", symbolsFlagName)
+		}
+		var from time.Time
+		if value, _ := cmd.Flags().GetString(fromFlagName); value != "" {
+			var err error
+			if from, err = time.ParseInLocation(dateFlagFormat, value, nse.IST); err != nil {
+				return fmt.Errorf("invalid --%s: %w", fromFlagName, err)
+			}
+		}
+		backend, closeBackend, err := openBackend(cmd)
+		if err != nil {
+			return err
+		}
+		defer closeBackend()
+		s := store.New(backend, client)
+
+		var failed []string
+		for _, symbol := range symbols {
+			opts, bar := fetchOptionsFlags(cmd)
+			result, err := s.Sync(cmd.Context(), symbol, from, opts)
+			if bar != nil && result != nil {
+				bar.finish()
+			}
+			if result == nil {
+				return err
+			}
+			fmt.Printf("%s: %d new days\n", result.Symbol, result.Added)
+			if err != nil {
+				for _, gap := range result.Gaps {
+					fmt.Fprintf(os.Stderr, "%s: missing %s to %s\n", result.Symbol, gap.Start.Format(dateFlagFormat), gap.End.Format(dateFlagFormat))
+				}
+				failed = append(failed, result.Symbol)
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("sync incomplete for %s, run it again to fetch the gaps", strings.Join(failed, ", "))
+		}
+		return nil
 	},
 }
 
+// storeDir returns the directory given by --dir or the default one
+func storeDir(cmd *cobra.Command) (string, error) {
+	if dir, _ := cmd.Flags().GetString(dirFlagName); dir != "" {
+		return dir, nil
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no --%s given: %w", dirFlagName, err)
+	}
+	return filepath.Join(cache, "nse", "history"), nil
+}
+
+// openBackend opens the store backend given by --backend in the directory
+// given by --dir. close releases it.
+func openBackend(cmd *cobra.Command) (store.Backend, func() error, error) {
+	dir, err := storeDir(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch kind, _ := cmd.Flags().GetString(backendFlagName); kind {
+	case "file":
+		backend, err := store.NewFileBackend(dir)
+		return backend, func() error { return nil }, err
+	case "sqlite":
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, nil, err
+		}
+		db, err := sql.Open("sqlite", filepath.Join(dir, sqliteFileName))
+		if err != nil {
+			return nil, nil, err
+		}
+		backend, err := store.NewSQLite(cmd.Context(), db)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return backend, db.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid --%s %q, want file or sqlite", backendFlagName, kind)
+	}
+}
+
+// historyFlags downloads the history of symbol over the range given by
+// --from and --to, resampled to --interval. failed are the chunks that could
+// not be downloaded.
+func historyFlags(cmd *cobra.Command, symbol string) (candles nse.Candles, failed []nse.ChunkFailure, err error) {
+	interval, _ := cmd.Flags().GetString(intervalFlagName)
+	period, err := nse.ParsePeriod(interval)
+	if err != nil {
+		return nil, nil, err
+	}
+	ranges, err := dateRangeFlags(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts, bar := fetchOptionsFlags(cmd)
+	result, err := client.EquityHistoryChunks(cmd.Context(), symbol, ranges, opts)
+	if bar != nil && result != nil {
+		bar.finish()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return nse.EquityHistoryCandles(result.Data).Resample(period), result.Failed, nil
+}
+
+// fetchOptionsFlags returns the download options given by --parallel and
+// --retries, with a progress bar if stderr is a terminal
+func fetchOptionsFlags(cmd *cobra.Command) (nse.FetchOptions, *progressBar) {
+	parallel, _ := cmd.Flags().GetInt(parallelFlagName)
+	retries, _ := cmd.Flags().GetInt(retriesFlagName)
+	opts := nse.FetchOptions{
+		Parallelism: parallel,
+		Retry:       nse.RetryPolicy{MaxRetries: retries, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+	}
+	bar := newProgressBar()
+	if bar != nil {
+		opts.Progress = bar.update
+	}
+	return opts, bar
+}
+
+// gapsError prints the ranges of failed chunks to stderr and returns them as
+// an error, or nil if none failed
+func gapsError(name string, failed []nse.ChunkFailure) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	for _, f := range failed {
+		fmt.Fprintf(os.Stderr, "missing %s to %s\n", f.Range.Start.Format(dateFlagFormat), f.Range.End.Format(dateFlagFormat))
+	}
+	return &nse.ChunkError{Symbol: name, Failed: failed}
+}
+
+// dateRangeFlags returns the range given by --from and --to, or nil when
+// neither is set
+func dateRangeFlags(cmd *cobra.Command) ([]nse.DateRange, error) {
+	from, _ := cmd.Flags().GetString(fromFlagName)
+	to, _ := cmd.Flags().GetString(toFlagName)
+	if from == "" && to == "" {
+		return nil, nil
+	}
+	if from == "" {
+		return nil, fmt.Errorf("--%s is required with --%s", fromFlagName, toFlagName)
+	}
+
+	var r nse.DateRange
+	var err error
+	if r.Start, err = time.ParseInLocation(dateFlagFormat, from, nse.IST); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", fromFlagName, err)
+	}
+	r.End = time.Now().In(nse.IST)
+	if to != "" {
+		if r.End, err = time.ParseInLocation(dateFlagFormat, to, nse.IST); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", toFlagName, err)
+		}
+	}
+	if r.End.Before(r.Start) {
+		return nil, fmt.Errorf("--%s is after --%s", fromFlagName, toFlagName)
+	}
+	return []nse.DateRange{r}, nil
+}
+
+// printCandles prints candles as an aligned table
+func printCandles(candles nse.Candles) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Date\tOpen\tHigh\tLow\tClose\tVolume\t")
+	for _, c := range candles {
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t\n", c.Time.Format(dateFlagFormat), c.Open, c.High, c.Low, c.Close, c.Volume)
+	}
+	w.Flush()
+}
+
 func init() {
+	rootCmd.PersistentFlags().BoolP(verboseFlagName, verboseFlagShort, false, verboseFlagDescription)
 	quoteEquityCmd.Flags().StringP(symbolFlagName, symbolFlagShort, symbolFlagDefault, symbolFlagDescription)
 
-	rootCmd.AddCommand(helpCmd, symbolCmd, quoteEquityCmd)
+	historyCmd.Flags().StringP(symbolFlagName, symbolFlagShort, symbolFlagDefault, symbolFlagDescription)
+	historyCmd.Flags().String(fromFlagName, "", fromFlagDescription)
+	historyCmd.Flags().String(toFlagName, "", toFlagDescription)
+	historyCmd.Flags().Int(parallelFlagName, parallelFlagDefault, parallelFlagDescription)
+	historyCmd.Flags().Int(retriesFlagName, retriesFlagDefault, retriesFlagDescription)
+	historyCmd.Flags().StringP(intervalFlagName, intervalFlagShort, intervalFlagDefault, intervalFlagDescription)
+
+	indexHistoryCmd.Flags().StringP(indexNameFlagName, indexNameFlagShort, indexNameFlagDefault, indexNameFlagDescription)
+	indexHistoryCmd.Flags().String(fromFlagName, "", indexFromFlagDescription)
+	indexHistoryCmd.Flags().String(toFlagName, "", toFlagDescription)
+	indexHistoryCmd.Flags().Int(parallelFlagName, parallelFlagDefault, parallelFlagDescription)
+	indexHistoryCmd.Flags().Int(retriesFlagName, retriesFlagDefault, retriesFlagDescription)
+
+	syncCmd.Flags().StringSlice(symbolsFlagName, nil, symbolsFlagDescription)
+	syncCmd.Flags().String(dirFlagName, "", dirFlagDescription)
+	syncCmd.Flags().String(backendFlagName, backendFlagDefault, backendFlagDescription)
+	syncCmd.Flags().String(fromFlagName, "", syncFromFlagDescription)
+	syncCmd.Flags().Int(parallelFlagName, parallelFlagDefault, parallelFlagDescription)
+	syncCmd.Flags().Int(retriesFlagName, retriesFlagDefault, retriesFlagDescription)
+
+	rootCmd.AddCommand(helpCmd, symbolCmd, quoteEquityCmd, historyCmd, indexHistoryCmd, syncCmd)
 
 }
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		log.Fatal(err)
 	}
 }
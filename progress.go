@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"nse/lib/nse"
+)
+
+const progressBarWidth = 30
+
+// progressBar draws the progress of a chunked download on one terminal line
+type progressBar struct {
+	w io.Writer
+}
+
+// newProgressBar returns a bar drawing on stderr, or nil if stderr is not a
+// terminal
+func newProgressBar() *progressBar {
+	info, err := os.Stderr.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return &progressBar{w: os.Stderr}
+}
+
+// update redraws the bar; it can be used as nse.FetchOptions.Progress
+func (b *progressBar) update(p nse.FetchProgress) {
+	filled := 0
+	if p.Total > 0 {
+		filled = progressBarWidth * p.Done / p.Total
+	}
+	line := fmt.Sprintf("\r[%s%s] %d/%d chunks, %s",
+		strings.Repeat("#", filled), strings.Repeat(" ", progressBarWidth-filled), p.Done, p.Total, formatBytes(p.Bytes))
+	if p.Failed > 0 {
+		line += fmt.Sprintf(", %d failed", p.Failed)
+	}
+	fmt.Fprint(b.w, line)
+}
+
+// finish ends the line of the bar
+func (b *progressBar) finish() {
+	fmt.Fprintln(b.w)
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}